@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/marksaravi/drawings-go/devices/converter"
+	"github.com/marksaravi/drawings-go/devices/memory"
+	"github.com/marksaravi/drawings-go/devices/terminal"
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/protocol"
+	"github.com/marksaravi/drawings-go/recorder"
+	"github.com/marksaravi/drivers-go/colors"
+	"github.com/marksaravi/drivers-go/hardware/gpio"
+	"github.com/marksaravi/drivers-go/hardware/ili9341"
+	"github.com/marksaravi/drivers-go/hardware/spi"
+	"periph.io/x/host/v3"
+)
+
+// server owns the panel, every client draws into its own viewport and clients are refused
+// while all the viewports are in use. The commands of a frame are kept until the client's
+// Update and then drawn in one go, so other clients never see half frames.
+type server struct {
+	mu        sync.Mutex
+	panel     pixelDevice
+	viewports []*viewport
+	pngPath   string
+}
+
+// MAX_FRAME_COMMANDS is the most commands a client may send before its Update, the points
+// of polylines and paths count as commands. Clients sending more are disconnected.
+const MAX_FRAME_COMMANDS = 100000
+
+// MAX_COORDINATE limits the numbers of the commands, such as coordinates, radii and widths,
+// so no command of a client keeps the panel locked for long.
+const MAX_COORDINATE = 4096
+
+func main() {
+	listen := flag.String("listen", "unix:/tmp/sketch-server.sock", "unix:<path> or tcp:<host:port> to listen on")
+	backend := flag.String("backend", "ili9341", "ili9341, memory or terminal")
+	width := flag.Int("width", 320, "screen width of the memory and terminal backends")
+	height := flag.Int("height", 240, "screen height of the memory and terminal backends")
+	pngPath := flag.String("png", "", "memory backend: write the screen to this PNG file after every frame")
+	viewports := flag.String("viewports", "", "client areas as x,y,width,height;... given to clients in order, default the whole panel for a single client")
+	flag.Parse()
+
+	srv := &server{pngPath: *pngPath}
+	switch *backend {
+	case "memory":
+		srv.panel = memory.NewMemoryDevice(*width, *height)
+	case "terminal":
+		srv.panel = newTerminal(*width, *height)
+	case "ili9341":
+		srv.panel = newILI9341()
+	default:
+		log.Fatalf("unknown backend %q", *backend)
+	}
+	var err error
+	srv.viewports, err = parseViewports(*viewports, srv.panel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv.panel.Clear(colors.BLACK)
+	srv.panel.Update()
+
+	network, address, ok := strings.Cut(*listen, ":")
+	if !ok || (network != "unix" && network != "tcp") {
+		log.Fatalf("invalid listen address %q", *listen)
+	}
+	if network == "unix" {
+		os.Remove(address)
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		listener.Close()
+	}()
+	fmt.Println("sketch-server listening on", *listen)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Println(err)
+			continue
+		}
+		go srv.serve(conn)
+	}
+}
+
+// newTerminal draws the screen on stdout, scaled down to the size of the terminal.
+func newTerminal(width, height int) pixelDevice {
+	dev := terminal.NewTerminalDevice(width, height, os.Stdout)
+	if columns, rows, err := terminal.Size(os.Stdout); err == nil {
+		dev.SetTerminalSize(columns, rows)
+	}
+	return dev
+}
+
+// newILI9341 converts the colours to RGB565 as clients may send any drawings colour.
+func newILI9341() pixelDevice {
+	host.Init()
+	spiConn := spi.NewSPI(1, 0, spi.Mode2, 64, 8)
+	dataCommandSelect := gpio.NewGPIOOut("GPIO22")
+	reset := gpio.NewGPIOOut("GPIO23")
+	dev, err := ili9341.NewILI9341(ili9341.LCD_320x200, spiConn, dataCommandSelect, reset)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return converter.NewConverterDevice(dev, converter.ToRGB565)
+}
+
+func (srv *server) acquire() *viewport {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for _, v := range srv.viewports {
+		if !v.inUse {
+			v.inUse = true
+			return v
+		}
+	}
+	return nil
+}
+
+func (srv *server) release(v *viewport) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	v.inUse = false
+}
+
+func (srv *server) serve(conn net.Conn) {
+	defer conn.Close()
+	v := srv.acquire()
+	if v == nil {
+		protocol.WriteAck(conn, 0, errors.New("no free viewport, see -viewports"))
+		return
+	}
+	defer srv.release(v)
+	log.Printf("client %s: viewport %d,%d %dx%d", conn.RemoteAddr(), v.x, v.y, v.width, v.height)
+
+	sketcher := drawings.NewSketcher(v, colors.BLACK)
+	decoder := protocol.NewDecoder(conn)
+	pending := make([]recorder.Command, 0)
+	size := 0
+	frame := 0
+	for {
+		c, err := decoder.Decode()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Printf("client %s: %v", conn.RemoteAddr(), err)
+			protocol.WriteAck(conn, frame+1, err)
+			return
+		}
+		if c.Name != "Update" {
+			size += commandSize(c)
+			if size > MAX_FRAME_COMMANDS {
+				err := fmt.Errorf("more than %d commands in frame %d", MAX_FRAME_COMMANDS, frame+1)
+				log.Printf("client %s: %v", conn.RemoteAddr(), err)
+				protocol.WriteAck(conn, frame+1, err)
+				return
+			}
+			pending = append(pending, c)
+			continue
+		}
+		frame++
+		err = srv.drawFrame(sketcher, pending)
+		pending = pending[:0]
+		size = 0
+		if err := protocol.WriteAck(conn, frame, err); err != nil {
+			return
+		}
+	}
+}
+
+// drawFrame checks the arguments of all the commands before drawing any of them on the panel.
+func (srv *server) drawFrame(sketcher drawings.Sketcher, commands []recorder.Command) error {
+	for _, c := range commands {
+		if err := c.Check(); err != nil {
+			return err
+		}
+		if err := checkNumbers(c); err != nil {
+			return err
+		}
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var err error
+	for _, c := range commands {
+		if applyErr := c.Apply(sketcher); applyErr != nil && err == nil {
+			err = applyErr
+		}
+	}
+	if err == nil {
+		err = sketcher.Err()
+	}
+	sketcher.Update()
+	if srv.pngPath != "" {
+		if err := srv.writePNG(); err != nil {
+			log.Println(err)
+		}
+	}
+	return err
+}
+
+// commandSize is the command and the points of its polylines and paths.
+func commandSize(c recorder.Command) int {
+	size := 1
+	for _, arg := range c.Args {
+		switch v := arg.(type) {
+		case []drawings.Point:
+			size += len(v)
+		case *drawings.Path:
+			for _, s := range v.Segments() {
+				size += len(s.Points)
+			}
+		}
+	}
+	return size
+}
+
+// checkNumbers tells if the numbers and points of the command are finite and within
+// MAX_COORDINATE.
+func checkNumbers(c recorder.Command) error {
+	check := func(v float64) error {
+		if !(math.Abs(v) <= MAX_COORDINATE) {
+			return fmt.Errorf("%s: %v is not within ±%d", c.Name, v, MAX_COORDINATE)
+		}
+		return nil
+	}
+	checkPoints := func(points []drawings.Point) error {
+		for _, p := range points {
+			if err := check(p.X); err != nil {
+				return err
+			}
+			if err := check(p.Y); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, arg := range c.Args {
+		var err error
+		switch v := arg.(type) {
+		case float64:
+			err = check(v)
+		case []drawings.Point:
+			err = checkPoints(v)
+		case *drawings.Path:
+			for _, s := range v.Segments() {
+				if err = checkPoints(s.Points); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePNG replaces the PNG file at once so viewers never read a partial image.
+func (srv *server) writePNG() error {
+	dev, ok := srv.panel.(interface{ WritePNG(w io.Writer) error })
+	if !ok {
+		return errors.New("-png needs the memory backend")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(srv.pngPath), ".sketch-*.png")
+	if err != nil {
+		return err
+	}
+	if err := dev.WritePNG(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), srv.pngPath)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pixelDevice interface {
+	Pixel(x, y int, color any) error
+	Clear(color any) error
+	Update() int
+	ScreenWidth() int
+	ScreenHeight() int
+}
+
+// viewport is the part of the panel given to one client, it clips the drawing to its area.
+type viewport struct {
+	panel  pixelDevice
+	x      int
+	y      int
+	width  int
+	height int
+	inUse  bool
+}
+
+func (v *viewport) ScreenWidth() int {
+	return v.width
+}
+
+func (v *viewport) ScreenHeight() int {
+	return v.height
+}
+
+func (v *viewport) Pixel(x, y int, color any) error {
+	if x < 0 || y < 0 || x >= v.width || y >= v.height {
+		return nil
+	}
+	return v.panel.Pixel(v.x+x, v.y+y, color)
+}
+
+// Clear only clears the area of the viewport.
+func (v *viewport) Clear(color any) error {
+	for y := 0; y < v.height; y++ {
+		for x := 0; x < v.width; x++ {
+			if err := v.panel.Pixel(v.x+x, v.y+y, color); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *viewport) Update() int {
+	return v.panel.Update()
+}
+
+// parseViewports reads "x,y,width,height" areas separated by semicolons, an empty list is the whole panel,
+// given to a single client at a time.
+func parseViewports(list string, panel pixelDevice) ([]*viewport, error) {
+	if strings.TrimSpace(list) == "" {
+		return []*viewport{{panel: panel, width: panel.ScreenWidth(), height: panel.ScreenHeight()}}, nil
+	}
+	viewports := make([]*viewport, 0)
+	for _, area := range strings.Split(list, ";") {
+		fields := strings.Split(area, ",")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("viewport %q is not x,y,width,height", area)
+		}
+		var values [4]int
+		for i, f := range fields {
+			v, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return nil, fmt.Errorf("viewport %q: %v", area, err)
+			}
+			values[i] = v
+		}
+		v := &viewport{panel: panel, x: values[0], y: values[1], width: values[2], height: values[3]}
+		if v.x < 0 || v.y < 0 || v.width <= 0 || v.height <= 0 ||
+			v.x+v.width > panel.ScreenWidth() || v.y+v.height > panel.ScreenHeight() {
+			return nil, fmt.Errorf("viewport %q is outside the panel", area)
+		}
+		viewports = append(viewports, v)
+	}
+	return viewports, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marksaravi/drawings-go/devices/memory"
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/internal/scenarios"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+//go:embed index.html
+var indexPage []byte
+
+type screen interface {
+	Pixel(x, y int, color any) error
+	Clear(color any) error
+	Update() int
+	ScreenWidth() int
+	ScreenHeight() int
+	WritePNG(w io.Writer) error
+}
+
+type frameEvent struct {
+	Frame    int    `json:"frame"`
+	Scenario string `json:"scenario"`
+}
+
+// simulator is the pixel device of the sketcher, every Update is sent to the open pages.
+type simulator struct {
+	screen
+	mu        sync.Mutex
+	listeners map[chan frameEvent]bool
+	last      frameEvent
+}
+
+func newSimulator(width, height int) *simulator {
+	return &simulator{
+		screen:    memory.NewMemoryDevice(width, height),
+		listeners: make(map[chan frameEvent]bool),
+	}
+}
+
+func (sim *simulator) setScenario(name string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.last.Scenario = name
+}
+
+func (sim *simulator) Update() int {
+	changed := sim.screen.Update()
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.last.Frame++
+	for l := range sim.listeners {
+		// a slow page skips frames, it always loads the latest one
+		select {
+		case l <- sim.last:
+		default:
+		}
+	}
+	return changed
+}
+
+func (sim *simulator) listen() (chan frameEvent, frameEvent) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	l := make(chan frameEvent, 1)
+	sim.listeners[l] = true
+	return l, sim.last
+}
+
+func (sim *simulator) unlisten(l chan frameEvent) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	delete(sim.listeners, l)
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address of the web page")
+	width := flag.Int("width", 320, "screen width")
+	height := flag.Int("height", 240, "screen height")
+	name := flag.String("scenario", "", "run only this test-sketcher scenario, default all of them")
+	interval := flag.Duration("interval", 2*time.Second, "time each scenario stays on the screen")
+	loop := flag.Bool("loop", true, "start again after the last scenario")
+	flag.Parse()
+
+	tests := scenarios.All
+	if *name != "" {
+		s, ok := scenarios.Find(*name)
+		if !ok {
+			log.Fatalf("unknown scenario %q", *name)
+		}
+		tests = []scenarios.Scenario{s}
+	}
+
+	sim := newSimulator(*width, *height)
+	sketcher := drawings.NewSketcher(sim, colors.BLACK)
+	sketcher.Clear(colors.WHITE)
+	sketcher.Update()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexPage)
+	})
+	http.HandleFunc("/frame.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := sim.WritePNG(w); err != nil {
+			log.Println(err)
+		}
+	})
+	http.HandleFunc("/events", sim.serveEvents)
+
+	go runScenarios(sim, sketcher, tests, *interval, *loop)
+
+	fmt.Printf("sketch-sim on http://%s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// serveEvents sends a server-sent event for every Update, starting with the current frame.
+func (sim *simulator) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	l, current := sim.listen()
+	defer sim.unlisten(l)
+	for {
+		data, _ := json.Marshal(current)
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case current = <-l:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func runScenarios(sim *simulator, sketcher drawings.Sketcher, tests []scenarios.Scenario, interval time.Duration, loop bool) {
+	for {
+		for _, s := range tests {
+			sim.setScenario(s.Name)
+			sketcher.Clear(colors.WHITE)
+			s.Draw(sketcher)
+			if err := sketcher.Err(); err != nil {
+				log.Printf("%s: %v", s.Name, err)
+			}
+			sketcher.Update()
+			time.Sleep(interval)
+		}
+		if !loop {
+			return
+		}
+	}
+}
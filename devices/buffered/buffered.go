@@ -0,0 +1,122 @@
+package buffered
+
+import (
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+// DRAWN marks the buffered pixels that have been drawn, the colour is in the lower 24 bits.
+const DRAWN uint32 = 1 << 24
+
+type pixelDevice interface {
+	Pixel(x, y int, color any) error
+	Clear(color any) error
+	Update() int
+	ScreenWidth() int
+	ScreenHeight() int
+}
+
+// device draws into a back buffer and only forwards the pixels that differ from
+// the front buffer to the underlying device on Update. The pixels are forwarded as
+// drivers-go RGB888 colours, which the panel drivers take.
+type device struct {
+	dev    pixelDevice
+	width  int
+	height int
+	back   []uint32
+	front  []uint32
+	err    error
+}
+
+func NewBufferedDevice(dev pixelDevice) *device {
+	width := dev.ScreenWidth()
+	height := dev.ScreenHeight()
+	return &device{
+		dev:    dev,
+		width:  width,
+		height: height,
+		back:   make([]uint32, width*height),
+		front:  make([]uint32, width*height),
+		err:    nil,
+	}
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+// toBuffered converts any colour drawings.ToColor takes to a drawn buffer pixel.
+func toBuffered(c any) (uint32, error) {
+	rgb, err := drawings.ToColor(c)
+	if err != nil {
+		return 0, err
+	}
+	return DRAWN | uint32(rgb.R)<<16 | uint32(rgb.G)<<8 | uint32(rgb.B), nil
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	c, err := toBuffered(color)
+	if err != nil {
+		return err
+	}
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		return nil
+	}
+	dev.back[y*dev.width+x] = c
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	c, err := toBuffered(color)
+	if err != nil {
+		return err
+	}
+	for i := range dev.back {
+		dev.back[i] = c
+	}
+	return nil
+}
+
+// Update flips the back buffer to the screen and returns the number of changed horizontal spans.
+// Err reports the first error of the underlying device until the next Update.
+func (dev *device) Update() int {
+	dev.err = nil
+	spans := 0
+	for y := 0; y < dev.height; y++ {
+		row := y * dev.width
+		inSpan := false
+		for x := 0; x < dev.width; x++ {
+			i := row + x
+			c := dev.back[i]
+			if c&DRAWN == 0 || c == dev.front[i] {
+				inSpan = false
+				continue
+			}
+			if !inSpan {
+				spans++
+				inSpan = true
+			}
+			// pixels the device fails to draw are sent again on the next Update
+			if err := dev.dev.Pixel(x, y, colors.RGB888(c&^DRAWN)); err != nil {
+				if dev.err == nil {
+					dev.err = err
+				}
+				continue
+			}
+			dev.front[i] = c
+		}
+	}
+	if spans > 0 {
+		dev.dev.Update()
+	}
+	return spans
+}
+
+// Err returns the first error of the underlying device in the last Update.
+func (dev *device) Err() error {
+	return dev.err
+}
@@ -0,0 +1,96 @@
+package buffered
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+// recordingDevice records the forwarded pixels and fails on the pixels of failAt.
+type recordingDevice struct {
+	pixels  map[[2]int]any
+	updates int
+	failAt  map[[2]int]bool
+}
+
+func (dev *recordingDevice) Pixel(x, y int, color any) error {
+	if dev.failAt[[2]int{x, y}] {
+		return errors.New("pixel failed")
+	}
+	dev.pixels[[2]int{x, y}] = color
+	return nil
+}
+
+func (dev *recordingDevice) Clear(color any) error { return nil }
+func (dev *recordingDevice) Update() int           { dev.updates++; return 0 }
+func (dev *recordingDevice) ScreenWidth() int      { return 8 }
+func (dev *recordingDevice) ScreenHeight() int     { return 4 }
+
+func TestUpdateForwardsChangedSpans(t *testing.T) {
+	target := &recordingDevice{pixels: map[[2]int]any{}}
+	dev := NewBufferedDevice(target)
+	for x := 1; x <= 3; x++ {
+		dev.Pixel(x, 0, 0xFF0000)
+	}
+	dev.Pixel(6, 0, "blue")
+	dev.Pixel(2, 2, drawings.Color{G: 255})
+	if spans := dev.Update(); spans != 3 {
+		t.Errorf("first Update = %d spans, want 3", spans)
+	}
+	if len(target.pixels) != 5 || target.pixels[[2]int{6, 0}] != colors.RGB888(0x0000FF) {
+		t.Errorf("forwarded %v", target.pixels)
+	}
+
+	// the same colours in other types are not forwarded again
+	target.pixels = map[[2]int]any{}
+	dev.Pixel(1, 0, drawings.Color{R: 255})
+	dev.Pixel(2, 2, uint32(0x00FF00))
+	dev.Pixel(3, 0, 0xFF0001)
+	if spans := dev.Update(); spans != 1 || len(target.pixels) != 1 {
+		t.Errorf("second Update = %d spans and %v, want only (3, 0)", spans, target.pixels)
+	}
+	if spans := dev.Update(); spans != 0 || target.updates != 2 {
+		t.Errorf("unchanged Update = %d spans and %d device updates, want 0 and 2", spans, target.updates)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	target := &recordingDevice{pixels: map[[2]int]any{}, failAt: map[[2]int]bool{{1, 1}: true}}
+	s := drawings.NewSketcher(NewBufferedDevice(target), 0)
+	s.FillRectangle(0, 0, 2, 2, 0xFFFFFF)
+	if err := s.Err(); err != nil {
+		t.Fatalf("drawing failed: %v", err)
+	}
+	s.Update()
+	if err := s.Err(); err == nil {
+		t.Error("the error of the underlying device is not reported")
+	}
+	delete(target.pixels, [2]int{0, 0})
+	s.Update()
+	if err := s.Err(); err == nil {
+		t.Error("the pixel which failed is not sent again")
+	}
+	if _, ok := target.pixels[[2]int{0, 0}]; ok {
+		t.Error("a drawn pixel is sent again")
+	}
+
+	target.failAt = nil
+	s.Update()
+	if err := s.Err(); err != nil {
+		t.Errorf("Update after the device recovered reports %v", err)
+	}
+	if c := target.pixels[[2]int{1, 1}]; c != colors.RGB888(0xFFFFFF) {
+		t.Errorf("the failed pixel is sent as %v after the device recovered", c)
+	}
+	s.Update()
+	if err := s.Err(); err != nil {
+		t.Errorf("Update without changes reports %v", err)
+	}
+
+	s.Pixel(0, 0, struct{}{})
+	if err := s.Err(); !errors.Is(err, drawings.ErrUnsupportedColor) {
+		t.Errorf("unsupported colour reports %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package converter is a pixel device converting every colour to the pixel format of the
+// device it wraps, so the same drawing code runs on panels with different formats.
+package converter
+
+import (
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+type pixelDevice interface {
+	Pixel(x, y int, color any) error
+	Clear(color any) error
+	Update() int
+	ScreenWidth() int
+	ScreenHeight() int
+}
+
+// Convert returns the colour in the pixel format of a device.
+type Convert func(c drawings.Color) any
+
+func ToRGB565(c drawings.Color) any {
+	return colors.RGB565(c.RGB565())
+}
+
+func ToRGB888(c drawings.Color) any {
+	return colors.RGB888(c.RGB888())
+}
+
+// ToGrey converts to an RGB888 grey of the same luminance, e.g. to preview a greyscale panel.
+func ToGrey(c drawings.Color) any {
+	return colors.RGB888(drawings.Grey(c.Grey()).RGB888())
+}
+
+// device converts the colours, see drawings.ToColor for the colours it takes.
+type device struct {
+	dev     pixelDevice
+	convert Convert
+}
+
+func NewConverterDevice(dev pixelDevice, convert Convert) *device {
+	return &device{
+		dev:     dev,
+		convert: convert,
+	}
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.dev.ScreenWidth()
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.dev.ScreenHeight()
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	c, err := drawings.ToColor(color)
+	if err != nil {
+		return err
+	}
+	return dev.dev.Pixel(x, y, dev.convert(c))
+}
+
+func (dev *device) Clear(color any) error {
+	c, err := drawings.ToColor(color)
+	if err != nil {
+		return err
+	}
+	return dev.dev.Clear(dev.convert(c))
+}
+
+func (dev *device) Update() int {
+	return dev.dev.Update()
+}
@@ -0,0 +1,186 @@
+// Package fbdev is a pixel device for Linux framebuffers such as /dev/fb0, e.g. HDMI outputs
+// and panels with an fbdev driver.
+package fbdev
+
+import (
+	"fmt"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// bitfield is the position of a colour channel in a pixel.
+type bitfield struct {
+	offset uint32
+	length uint32
+}
+
+// format is the memory layout of the framebuffer.
+type format struct {
+	bitsPerPixel int
+	lineLength   int
+	red          bitfield
+	green        bitfield
+	blue         bitfield
+}
+
+// defaultFormat is the usual layout of a framebuffer with the bits per pixel: RGB565 for 16,
+// BGR for 24 and XRGB for 32.
+func defaultFormat(width, bitsPerPixel int) (format, error) {
+	f := format{
+		bitsPerPixel: bitsPerPixel,
+		lineLength:   width * bitsPerPixel / 8,
+	}
+	switch bitsPerPixel {
+	case 16:
+		f.red = bitfield{offset: 11, length: 5}
+		f.green = bitfield{offset: 5, length: 6}
+		f.blue = bitfield{offset: 0, length: 5}
+	case 24, 32:
+		f.red = bitfield{offset: 16, length: 8}
+		f.green = bitfield{offset: 8, length: 8}
+		f.blue = bitfield{offset: 0, length: 8}
+	default:
+		return f, fmt.Errorf("fbdev: %d bits per pixel is not supported", bitsPerPixel)
+	}
+	return f, nil
+}
+
+// device draws into a copy of the framebuffer memory and copies the changed part of every
+// row to the framebuffer on Update.
+type device struct {
+	width     int
+	height    int
+	format    format
+	bytes     int
+	mem       []byte
+	back      []byte
+	dirtyFrom []int
+	dirtyTo   []int
+	close     func() error
+}
+
+func newDevice(width, height int, f format, mem []byte, close func() error) (*device, error) {
+	if f.bitsPerPixel != 16 && f.bitsPerPixel != 24 && f.bitsPerPixel != 32 {
+		return nil, fmt.Errorf("fbdev: %d bits per pixel is not supported", f.bitsPerPixel)
+	}
+	for _, field := range []bitfield{f.red, f.green, f.blue} {
+		if field.length == 0 || field.length > 8 || field.offset+field.length > uint32(f.bitsPerPixel) {
+			return nil, fmt.Errorf("fbdev: unsupported pixel format %+v", f)
+		}
+	}
+	if len(mem) < f.lineLength*height {
+		return nil, fmt.Errorf("fbdev: %d bytes of memory is too small for %dx%d", len(mem), width, height)
+	}
+	dev := &device{
+		width:     width,
+		height:    height,
+		format:    f,
+		bytes:     f.bitsPerPixel / 8,
+		mem:       mem,
+		back:      make([]byte, f.lineLength*height),
+		dirtyFrom: make([]int, height),
+		dirtyTo:   make([]int, height),
+		close:     close,
+	}
+	copy(dev.back, mem)
+	for y := range dev.dirtyFrom {
+		dev.dirtyFrom[y] = width
+		dev.dirtyTo[y] = -1
+	}
+	return dev, nil
+}
+
+// toRGB888 converts any colour drawings.ToColor takes to 0xRRGGBB.
+func toRGB888(c any) (uint32, error) {
+	rgb, err := drawings.ToColor(c)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(rgb.R)<<16 | uint32(rgb.G)<<8 | uint32(rgb.B), nil
+}
+
+// encode packs an RGB888 colour into the pixel format.
+func (f format) encode(rgb uint32) uint32 {
+	channel := func(value uint32, field bitfield) uint32 {
+		return (value >> (8 - field.length)) << field.offset
+	}
+	return channel(rgb>>16&0xFF, f.red) | channel(rgb>>8&0xFF, f.green) | channel(rgb&0xFF, f.blue)
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+// BitsPerPixel returns the pixel size of the framebuffer.
+func (dev *device) BitsPerPixel() int {
+	return dev.format.bitsPerPixel
+}
+
+// put writes the little-endian pixel value at byte offset i of the back buffer.
+func (dev *device) put(i int, value uint32) {
+	for b := 0; b < dev.bytes; b++ {
+		dev.back[i+b] = byte(value >> (8 * b))
+	}
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	rgb, err := toRGB888(color)
+	if err != nil {
+		return err
+	}
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		return nil
+	}
+	dev.put(y*dev.format.lineLength+x*dev.bytes, dev.format.encode(rgb))
+	if x < dev.dirtyFrom[y] {
+		dev.dirtyFrom[y] = x
+	}
+	if x > dev.dirtyTo[y] {
+		dev.dirtyTo[y] = x
+	}
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	rgb, err := toRGB888(color)
+	if err != nil {
+		return err
+	}
+	value := dev.format.encode(rgb)
+	for y := 0; y < dev.height; y++ {
+		row := y * dev.format.lineLength
+		for x := 0; x < dev.width; x++ {
+			dev.put(row+x*dev.bytes, value)
+		}
+		dev.dirtyFrom[y] = 0
+		dev.dirtyTo[y] = dev.width - 1
+	}
+	return nil
+}
+
+// Update copies the drawn span of every row to the framebuffer and returns the number of spans.
+func (dev *device) Update() int {
+	spans := 0
+	for y := 0; y < dev.height; y++ {
+		if dev.dirtyTo[y] < dev.dirtyFrom[y] {
+			continue
+		}
+		row := y * dev.format.lineLength
+		from := row + dev.dirtyFrom[y]*dev.bytes
+		to := row + (dev.dirtyTo[y]+1)*dev.bytes
+		copy(dev.mem[from:to], dev.back[from:to])
+		dev.dirtyFrom[y] = dev.width
+		dev.dirtyTo[y] = -1
+		spans++
+	}
+	return spans
+}
+
+// Close unmaps the framebuffer and closes its file.
+func (dev *device) Close() error {
+	return dev.close()
+}
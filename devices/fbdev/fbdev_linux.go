@@ -0,0 +1,157 @@
+//go:build linux
+
+package fbdev
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	FBIOGET_VSCREENINFO = 0x4600
+	FBIOGET_FSCREENINFO = 0x4602
+)
+
+type fbBitfield struct {
+	offset   uint32
+	length   uint32
+	msbRight uint32
+}
+
+// varScreenInfo is struct fb_var_screeninfo of linux/fb.h.
+type varScreenInfo struct {
+	xres         uint32
+	yres         uint32
+	xresVirtual  uint32
+	yresVirtual  uint32
+	xoffset      uint32
+	yoffset      uint32
+	bitsPerPixel uint32
+	grayscale    uint32
+	red          fbBitfield
+	green        fbBitfield
+	blue         fbBitfield
+	transp       fbBitfield
+	nonstd       uint32
+	activate     uint32
+	height       uint32
+	width        uint32
+	accelFlags   uint32
+	pixclock     uint32
+	leftMargin   uint32
+	rightMargin  uint32
+	upperMargin  uint32
+	lowerMargin  uint32
+	hsyncLen     uint32
+	vsyncLen     uint32
+	sync         uint32
+	vmode        uint32
+	rotate       uint32
+	colorspace   uint32
+	reserved     [4]uint32
+}
+
+// fixScreenInfo is struct fb_fix_screeninfo of linux/fb.h.
+type fixScreenInfo struct {
+	id           [16]byte
+	smemStart    uintptr
+	smemLen      uint32
+	fbType       uint32
+	typeAux      uint32
+	visual       uint32
+	xpanstep     uint16
+	ypanstep     uint16
+	ywrapstep    uint16
+	lineLength   uint32
+	mmioStart    uintptr
+	mmioLen      uint32
+	accel        uint32
+	capabilities uint16
+	reserved     [2]uint16
+}
+
+func ioctl(f *os.File, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// NewFramebufferDevice maps a framebuffer device, the size and pixel format are read from the driver.
+func NewFramebufferDevice(path string) (*device, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	var vinfo varScreenInfo
+	var finfo fixScreenInfo
+	if err := ioctl(f, FBIOGET_VSCREENINFO, unsafe.Pointer(&vinfo)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("fbdev: FBIOGET_VSCREENINFO: %w", err)
+	}
+	if err := ioctl(f, FBIOGET_FSCREENINFO, unsafe.Pointer(&finfo)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("fbdev: FBIOGET_FSCREENINFO: %w", err)
+	}
+	pf := format{
+		bitsPerPixel: int(vinfo.bitsPerPixel),
+		lineLength:   int(finfo.lineLength),
+		red:          bitfield{offset: vinfo.red.offset, length: vinfo.red.length},
+		green:        bitfield{offset: vinfo.green.offset, length: vinfo.green.length},
+		blue:         bitfield{offset: vinfo.blue.offset, length: vinfo.blue.length},
+	}
+	// the visible screen starts at the panning offset of the virtual screen
+	start := int(vinfo.yoffset)*pf.lineLength + int(vinfo.xoffset)*pf.bitsPerPixel/8
+	return mapDevice(f, int(vinfo.xres), int(vinfo.yres), pf, start, int(finfo.smemLen))
+}
+
+// NewFileDevice maps a regular file as a framebuffer in the default layout of the bits per
+// pixel, the file is grown to the size of the screen. It stands in for /dev/fb0 in tests.
+func NewFileDevice(path string, width, height, bitsPerPixel int) (*device, error) {
+	pf, err := defaultFormat(width, bitsPerPixel)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	size := pf.lineLength * height
+	info, err := f.Stat()
+	if err == nil && info.Size() < int64(size) {
+		err = f.Truncate(int64(size))
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return mapDevice(f, width, height, pf, 0, size)
+}
+
+func mapDevice(f *os.File, width, height int, pf format, start, size int) (*device, error) {
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("fbdev: mmap: %w", err)
+	}
+	close := func() error {
+		err := syscall.Munmap(mem)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	if start < 0 || start > len(mem) {
+		close()
+		return nil, fmt.Errorf("fbdev: screen offset %d is outside the framebuffer", start)
+	}
+	dev, err := newDevice(width, height, pf, mem[start:], close)
+	if err != nil {
+		close()
+		return nil, err
+	}
+	return dev, nil
+}
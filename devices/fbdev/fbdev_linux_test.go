@@ -0,0 +1,84 @@
+//go:build linux
+
+package fbdev
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileDevice(t *testing.T) {
+	tests := []struct {
+		bitsPerPixel int
+		pixel        []byte
+	}{
+		{16, []byte{0x06, 0x11}},
+		{24, []byte{0x30, 0x20, 0x10}},
+		{32, []byte{0x30, 0x20, 0x10, 0x00}},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "fb")
+		dev, err := NewFileDevice(path, 3, 2, test.bitsPerPixel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dev.Pixel(1, 0, 0x102030)
+		dev.Pixel(2, 1, 0x102030)
+		dev.Pixel(3, 1, 0x102030)
+		dev.Pixel(-1, 0, 0x102030)
+
+		blank := bytes.Repeat([]byte{0}, len(test.pixel))
+		want := bytes.Join([][]byte{blank, test.pixel, blank, blank, blank, test.pixel}, nil)
+		if got := readFile(t, path); !bytes.Equal(got, bytes.Repeat([]byte{0}, len(want))) {
+			t.Errorf("%d bpp: the file is % x before the update", test.bitsPerPixel, got)
+		}
+		if spans := dev.Update(); spans != 2 {
+			t.Errorf("%d bpp: Update copied %d spans, want 2", test.bitsPerPixel, spans)
+		}
+		if got := readFile(t, path); !bytes.Equal(got, want) {
+			t.Errorf("%d bpp: the file is % x, want % x", test.bitsPerPixel, got, want)
+		}
+		if spans := dev.Update(); spans != 0 {
+			t.Errorf("%d bpp: a second Update copied %d spans", test.bitsPerPixel, spans)
+		}
+		if err := dev.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// a device on the same file starts from what is on the screen
+		dev, err = NewFileDevice(path, 3, 2, test.bitsPerPixel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dev.Pixel(0, 1, 0x102030)
+		dev.Update()
+		want = bytes.Join([][]byte{blank, test.pixel, blank, test.pixel, blank, test.pixel}, nil)
+		if got := readFile(t, path); !bytes.Equal(got, want) {
+			t.Errorf("%d bpp: after reopening the file is % x, want % x", test.bitsPerPixel, got, want)
+		}
+		if err := dev.Clear(0xFFFFFF); err != nil {
+			t.Fatal(err)
+		}
+		if spans := dev.Update(); spans != 2 {
+			t.Errorf("%d bpp: Update after Clear copied %d spans, want 2", test.bitsPerPixel, spans)
+		}
+		dev.Close()
+	}
+}
+
+func TestFileDeviceBitsPerPixel(t *testing.T) {
+	if _, err := NewFileDevice(filepath.Join(t.TempDir(), "fb"), 3, 2, 8); err == nil {
+		t.Error("8 bits per pixel is not supported")
+	}
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
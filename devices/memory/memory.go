@@ -0,0 +1,113 @@
+// Package memory is a software pixel device for running and testing drawings without a panel.
+package memory
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sync"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// device draws into an RGBA buffer and publishes it as the screen on Update, so readers
+// of Image always get complete frames.
+type device struct {
+	width  int
+	height int
+	back   *image.RGBA
+	mu     sync.Mutex
+	front  *image.RGBA
+	frames int
+}
+
+func NewMemoryDevice(width, height int) *device {
+	return &device{
+		width:  width,
+		height: height,
+		back:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		front:  image.NewRGBA(image.Rect(0, 0, width, height)),
+		frames: 0,
+	}
+}
+
+// toRGBA converts any colour drawings.ToColor takes.
+func toRGBA(c any) (color.RGBA, error) {
+	rgb, err := drawings.ToColor(c)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return color.RGBA{R: rgb.R, G: rgb.G, B: rgb.B, A: 0xFF}, nil
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	c, err := toRGBA(color)
+	if err != nil {
+		return err
+	}
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		return nil
+	}
+	dev.back.SetRGBA(x, y, c)
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	c, err := toRGBA(color)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(dev.back.Pix); i += 4 {
+		dev.back.Pix[i] = c.R
+		dev.back.Pix[i+1] = c.G
+		dev.back.Pix[i+2] = c.B
+		dev.back.Pix[i+3] = c.A
+	}
+	return nil
+}
+
+// Update publishes the drawing and returns the number of changed pixels.
+func (dev *device) Update() int {
+	dev.mu.Lock()
+	defer dev.mu.Unlock()
+	changed := 0
+	for i := 0; i < len(dev.back.Pix); i += 4 {
+		if dev.back.Pix[i] != dev.front.Pix[i] || dev.back.Pix[i+1] != dev.front.Pix[i+1] ||
+			dev.back.Pix[i+2] != dev.front.Pix[i+2] || dev.back.Pix[i+3] != dev.front.Pix[i+3] {
+			changed++
+		}
+	}
+	copy(dev.front.Pix, dev.back.Pix)
+	dev.frames++
+	return changed
+}
+
+// Image returns a copy of the screen as of the last Update.
+func (dev *device) Image() *image.RGBA {
+	dev.mu.Lock()
+	defer dev.mu.Unlock()
+	img := image.NewRGBA(dev.front.Rect)
+	copy(img.Pix, dev.front.Pix)
+	return img
+}
+
+// Frames returns the number of updates so far.
+func (dev *device) Frames() int {
+	dev.mu.Lock()
+	defer dev.mu.Unlock()
+	return dev.frames
+}
+
+// WritePNG encodes the screen as of the last Update.
+func (dev *device) WritePNG(w io.Writer) error {
+	return png.Encode(w, dev.Image())
+}
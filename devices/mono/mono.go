@@ -0,0 +1,227 @@
+// Package mono is a pixel device for 1-bit and greyscale panels such as SSD1306 OLEDs and
+// e-paper displays. Colours are dithered down to the depth of the panel and packed into
+// pages for the driver to push.
+package mono
+
+import (
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// Depth is the number of bits of a pixel.
+type Depth int
+
+const (
+	DEPTH_1 Depth = 1
+	DEPTH_2 Depth = 2
+	DEPTH_4 Depth = 4
+)
+
+type Dithering int
+
+const (
+	THRESHOLD       Dithering = 0
+	BAYER           Dithering = 1
+	FLOYD_STEINBERG Dithering = 2
+)
+
+const DEFAULT_THRESHOLD = 128
+
+// bayer is the 4×4 ordered dithering matrix.
+var bayer = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// device keeps the luminance of every pixel and converts the screen to the panel on Update.
+//
+// The buffer is organised in pages of 8/depth rows like the SSD1306 memory: byte
+// page*width+x holds the pixels of column x, the top row in the lowest bits. 0 is black
+// and the highest level white.
+type device struct {
+	width     int
+	height    int
+	depth     Depth
+	dithering Dithering
+	threshold uint8
+	grey      []uint8
+	buffer    []byte
+	flush     func(buffer []byte) error
+	unflushed bool
+	err       error
+}
+
+func NewMonoDevice(width, height int, depth Depth) *device {
+	if depth != DEPTH_2 && depth != DEPTH_4 {
+		depth = DEPTH_1
+	}
+	rowsPerPage := 8 / int(depth)
+	pages := (height + rowsPerPage - 1) / rowsPerPage
+	return &device{
+		width:     width,
+		height:    height,
+		depth:     depth,
+		dithering: THRESHOLD,
+		threshold: DEFAULT_THRESHOLD,
+		grey:      make([]uint8, width*height),
+		buffer:    make([]byte, pages*width),
+		flush:     nil,
+		unflushed: false,
+		err:       nil,
+	}
+}
+
+// luminance converts any colour drawings.ToColor takes to its grey level.
+func luminance(c any) (uint8, error) {
+	rgb, err := drawings.ToColor(c)
+	if err != nil {
+		return 0, err
+	}
+	return rgb.Grey(), nil
+}
+
+// SetDithering sets how grey levels between the levels of the panel are drawn.
+func (dev *device) SetDithering(dithering Dithering) {
+	dev.dithering = dithering
+}
+
+// SetThreshold sets the luminance from which a pixel is white with THRESHOLD dithering
+// on 1-bit panels, deeper panels take the nearest level.
+func (dev *device) SetThreshold(threshold uint8) {
+	dev.threshold = threshold
+}
+
+// SetFlush sets the function pushing the buffer to the panel, it is called on every Update
+// which changed the buffer.
+func (dev *device) SetFlush(flush func(buffer []byte) error) {
+	dev.flush = flush
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	l, err := luminance(color)
+	if err != nil {
+		return err
+	}
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		return nil
+	}
+	dev.grey[y*dev.width+x] = l
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	l, err := luminance(color)
+	if err != nil {
+		return err
+	}
+	for i := range dev.grey {
+		dev.grey[i] = l
+	}
+	return nil
+}
+
+// levels returns the quantized level of every pixel.
+func (dev *device) levels() []uint8 {
+	top := float64(int(1)<<dev.depth - 1)
+	levels := make([]uint8, len(dev.grey))
+	quantize := func(v float64) uint8 {
+		if v <= 0 {
+			return 0
+		}
+		if v >= top {
+			return uint8(top)
+		}
+		return uint8(v)
+	}
+	switch dev.dithering {
+	case BAYER:
+		for y := 0; y < dev.height; y++ {
+			for x := 0; x < dev.width; x++ {
+				i := y*dev.width + x
+				offset := (float64(bayer[y%4][x%4]) + 0.5) / 16
+				levels[i] = quantize(float64(dev.grey[i])*top/255 + offset)
+			}
+		}
+	case FLOYD_STEINBERG:
+		current := make([]float64, dev.width+2)
+		next := make([]float64, dev.width+2)
+		for y := 0; y < dev.height; y++ {
+			for x := 0; x < dev.width; x++ {
+				i := y*dev.width + x
+				v := float64(dev.grey[i]) + current[x+1]
+				level := quantize(v*top/255 + 0.5)
+				levels[i] = level
+				e := v - float64(level)*255/top
+				current[x+2] += e * 7 / 16
+				next[x] += e * 3 / 16
+				next[x+1] += e * 5 / 16
+				next[x+2] += e * 1 / 16
+			}
+			current, next = next, current
+			for x := range next {
+				next[x] = 0
+			}
+		}
+	default:
+		for i, v := range dev.grey {
+			if dev.depth == DEPTH_1 {
+				if v >= dev.threshold {
+					levels[i] = 1
+				}
+				continue
+			}
+			levels[i] = quantize(float64(v)*top/255 + 0.5)
+		}
+	}
+	return levels
+}
+
+// Update packs the screen into the buffer and returns the number of changed bytes. A buffer
+// the flush function failed to push is pushed again by the next Update.
+func (dev *device) Update() int {
+	dev.err = nil
+	bits := int(dev.depth)
+	rowsPerPage := 8 / bits
+	levels := dev.levels()
+	changed := 0
+	for page := 0; page*rowsPerPage < dev.height; page++ {
+		for x := 0; x < dev.width; x++ {
+			var b byte
+			for row := 0; row < rowsPerPage; row++ {
+				y := page*rowsPerPage + row
+				if y < dev.height {
+					b |= levels[y*dev.width+x] << (row * bits)
+				}
+			}
+			i := page*dev.width + x
+			if dev.buffer[i] != b {
+				dev.buffer[i] = b
+				changed++
+			}
+		}
+	}
+	if (changed > 0 || dev.unflushed) && dev.flush != nil {
+		dev.err = dev.flush(dev.buffer)
+		dev.unflushed = dev.err != nil
+	}
+	return changed
+}
+
+// Buffer returns the packed screen as of the last Update, it is changed by the next Update.
+func (dev *device) Buffer() []byte {
+	return dev.buffer
+}
+
+// Err returns the error of the flush function in the last Update.
+func (dev *device) Err() error {
+	return dev.err
+}
@@ -0,0 +1,93 @@
+package mono
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// TestBuffer draws a gradient from black on the left to almost white on the right, grey
+// 36*x in column x, and checks the packed buffer of every dithering.
+func TestBuffer(t *testing.T) {
+	tests := []struct {
+		depth     Depth
+		dithering Dithering
+		want      []byte
+	}{
+		{DEPTH_1, THRESHOLD, []byte{0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff}},
+		{DEPTH_1, BAYER, []byte{0x00, 0x00, 0xaa, 0x55, 0xaa, 0xdd, 0xbb, 0xff}},
+		{DEPTH_1, FLOYD_STEINBERG, []byte{0x00, 0x00, 0x48, 0x37, 0xc8, 0xbf, 0xf7, 0xff}},
+		{DEPTH_2, THRESHOLD, []byte{
+			0x00, 0x00, 0x55, 0x55, 0xaa, 0xaa, 0xff, 0xff,
+			0x00, 0x00, 0x55, 0x55, 0xaa, 0xaa, 0xff, 0xff,
+		}},
+		{DEPTH_2, BAYER, []byte{
+			0x00, 0x10, 0x45, 0x55, 0x99, 0xaa, 0xee, 0xff,
+			0x00, 0x10, 0x45, 0x55, 0x99, 0xaa, 0xee, 0xff,
+		}},
+		{DEPTH_2, FLOYD_STEINBERG, []byte{
+			0x00, 0x04, 0x55, 0x55, 0xaa, 0xaa, 0xbb, 0xff,
+			0x00, 0x11, 0x55, 0x55, 0xaa, 0xaa, 0xbb, 0xff,
+		}},
+	}
+	for _, test := range tests {
+		dev := NewMonoDevice(8, 8, test.depth)
+		dev.SetDithering(test.dithering)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				grey := uint8(36 * x)
+				dev.Pixel(x, y, drawings.Color{R: grey, G: grey, B: grey})
+			}
+		}
+		changed := dev.Update()
+		if got := dev.Buffer(); !bytes.Equal(got, test.want) {
+			t.Errorf("depth %d dithering %d: buffer %#v, want %#v", test.depth, test.dithering, got, test.want)
+		}
+		if want := len(test.want) - bytes.Count(test.want, []byte{0}); changed != want {
+			t.Errorf("depth %d dithering %d: Update changed %d bytes, want %d", test.depth, test.dithering, changed, want)
+		}
+		if changed := dev.Update(); changed != 0 {
+			t.Errorf("depth %d dithering %d: a second Update changed %d bytes", test.depth, test.dithering, changed)
+		}
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	dev := NewMonoDevice(2, 1, DEPTH_1)
+	dev.SetThreshold(64)
+	dev.Pixel(0, 0, drawings.Color{R: 63, G: 63, B: 63})
+	dev.Pixel(1, 0, drawings.Color{R: 64, G: 64, B: 64})
+	dev.Update()
+	if got, want := dev.Buffer(), []byte{0x00, 0x01}; !bytes.Equal(got, want) {
+		t.Errorf("buffer %#v, want %#v", got, want)
+	}
+}
+
+func TestFlushErrors(t *testing.T) {
+	dev := NewMonoDevice(2, 1, DEPTH_1)
+	flushes := 0
+	fail := true
+	dev.SetFlush(func(buffer []byte) error {
+		flushes++
+		if fail {
+			return errors.New("flush failed")
+		}
+		return nil
+	})
+	dev.Pixel(0, 0, 0xFFFFFF)
+	dev.Update()
+	if dev.Err() == nil {
+		t.Error("the flush error is not reported")
+	}
+	fail = false
+	dev.Update()
+	if dev.Err() != nil || flushes != 2 {
+		t.Errorf("Update after the panel recovered reports %v after %d flushes, want nil after 2", dev.Err(), flushes)
+	}
+	dev.Update()
+	if dev.Err() != nil || flushes != 2 {
+		t.Errorf("Update without changes reports %v after %d flushes, want nil after 2", dev.Err(), flushes)
+	}
+}
@@ -0,0 +1,140 @@
+package profiler
+
+import (
+	"fmt"
+	"io"
+)
+
+type pixelDevice interface {
+	Pixel(x, y int, color any) error
+	Clear(color any) error
+	Update() int
+	ScreenWidth() int
+	ScreenHeight() int
+}
+
+// Stats are the device calls made while a section was profiled.
+type Stats struct {
+	Calls       int // number of times the section was started
+	Pixels      int // Pixel calls
+	Duplicates  int // Pixel calls to a coordinate already written in the same call of the section
+	OutOfBounds int // Pixel calls outside of the screen
+	Clears      int // Clear calls
+	Updates     int // Update calls
+}
+
+// device counts the calls forwarded to the underlying device, grouped by the section started with Begin.
+type device struct {
+	dev      pixelDevice
+	width    int
+	height   int
+	written  []uint32
+	run      uint32
+	section  string
+	sections []string
+	stats    map[string]*Stats
+}
+
+func NewProfilerDevice(dev pixelDevice) *device {
+	width := dev.ScreenWidth()
+	height := dev.ScreenHeight()
+	p := &device{
+		dev:      dev,
+		width:    width,
+		height:   height,
+		written:  make([]uint32, width*height),
+		run:      0,
+		sections: make([]string, 0),
+		stats:    make(map[string]*Stats),
+	}
+	p.newRun()
+	return p
+}
+
+// Begin attributes the following calls to the named section, e.g. the primitive about to be drawn.
+// Duplicate writes are counted from the last Begin.
+func (dev *device) Begin(section string) {
+	dev.section = section
+	dev.newRun()
+	dev.current().Calls++
+}
+
+func (dev *device) newRun() {
+	dev.run++
+	if dev.run == 0 {
+		for i := range dev.written {
+			dev.written[i] = 0
+		}
+		dev.run = 1
+	}
+}
+
+func (dev *device) current() *Stats {
+	s, ok := dev.stats[dev.section]
+	if !ok {
+		s = &Stats{}
+		dev.stats[dev.section] = s
+		dev.sections = append(dev.sections, dev.section)
+	}
+	return s
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	s := dev.current()
+	s.Pixels++
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		s.OutOfBounds++
+	} else {
+		i := y*dev.width + x
+		if dev.written[i] == dev.run {
+			s.Duplicates++
+		}
+		dev.written[i] = dev.run
+	}
+	return dev.dev.Pixel(x, y, color)
+}
+
+func (dev *device) Clear(color any) error {
+	dev.current().Clears++
+	return dev.dev.Clear(color)
+}
+
+func (dev *device) Update() int {
+	dev.current().Updates++
+	return dev.dev.Update()
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+// Stats returns the counters of a section.
+func (dev *device) Stats(section string) Stats {
+	if s, ok := dev.stats[section]; ok {
+		return *s
+	}
+	return Stats{}
+}
+
+// Reset clears the counters of all sections.
+func (dev *device) Reset() {
+	dev.sections = dev.sections[:0]
+	dev.stats = make(map[string]*Stats)
+	dev.newRun()
+}
+
+// Report writes the counters of every section in the order they were first used.
+func (dev *device) Report(w io.Writer) {
+	fmt.Fprintf(w, "%-24s %8s %10s %10s %10s\n", "section", "calls", "pixels", "duplicate", "outside")
+	for _, name := range dev.sections {
+		s := dev.stats[name]
+		if s.Pixels == 0 && s.Clears == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-24s %8d %10d %10d %10d\n", name, s.Calls, s.Pixels, s.Duplicates, s.OutOfBounds)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package terminal
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Size returns the number of columns and rows of the terminal of f.
+func Size(f *os.File) (int, int, error) {
+	var size struct {
+		rows    uint16
+		columns uint16
+		x       uint16
+		y       uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0, 0, errno
+	}
+	return int(size.columns), int(size.rows), nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package terminal
+
+import (
+	"errors"
+	"os"
+)
+
+// Size returns the number of columns and rows of the terminal of f.
+func Size(f *os.File) (int, int, error) {
+	return 0, 0, errors.New("terminal: size is only known on linux")
+}
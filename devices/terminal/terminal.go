@@ -0,0 +1,184 @@
+// Package terminal is a pixel device that shows the screen in a terminal with 24-bit ANSI colours,
+// e.g. to check over SSH what the panel shows.
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// UPPER_HALF_BLOCK is drawn in every cell, its foreground is the upper pixel and its background the lower one.
+const UPPER_HALF_BLOCK = "▀"
+
+// device keeps the screen in an RGB888 buffer and redraws the changed terminal cells on Update.
+// A cell shows two pixels above each other, a screen larger than the terminal is scaled down
+// by averaging blocks of scale×scale pixels.
+type device struct {
+	out    io.Writer
+	width  int
+	height int
+	pixels []uint32
+	scale  int
+	front  []uint32
+	drawn  bool
+	err    error
+}
+
+func NewTerminalDevice(width, height int, out io.Writer) *device {
+	return &device{
+		out:    out,
+		width:  width,
+		height: height,
+		pixels: make([]uint32, width*height),
+		scale:  1,
+		drawn:  false,
+		err:    nil,
+	}
+}
+
+// toRGB888 converts any colour drawings.ToColor takes to 0xRRGGBB.
+func toRGB888(c any) (uint32, error) {
+	rgb, err := drawings.ToColor(c)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(rgb.R)<<16 | uint32(rgb.G)<<8 | uint32(rgb.B), nil
+}
+
+// SetTerminalSize scales the screen down until it fits into the terminal, one row is kept
+// free for the prompt. A size of 0 draws the screen at full size.
+func (dev *device) SetTerminalSize(columns, rows int) {
+	scale := 1
+	if columns > 0 && rows > 1 {
+		for (dev.width+scale-1)/scale > columns || (dev.height+scale-1)/scale > 2*(rows-1) {
+			scale++
+		}
+	}
+	if scale != dev.scale {
+		dev.scale = scale
+		dev.drawn = false
+	}
+}
+
+// Scale returns the number of screen pixels averaged into one terminal pixel in each direction.
+func (dev *device) Scale() int {
+	return dev.scale
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	c, err := toRGB888(color)
+	if err != nil {
+		return err
+	}
+	if x < 0 || y < 0 || x >= dev.width || y >= dev.height {
+		return nil
+	}
+	dev.pixels[y*dev.width+x] = c
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	c, err := toRGB888(color)
+	if err != nil {
+		return err
+	}
+	for i := range dev.pixels {
+		dev.pixels[i] = c
+	}
+	return nil
+}
+
+// cells returns the size of the picture in terminal cells.
+func (dev *device) cells() (int, int) {
+	columns := (dev.width + dev.scale - 1) / dev.scale
+	rows := ((dev.height+dev.scale-1)/dev.scale + 1) / 2
+	return columns, rows
+}
+
+// average returns the colour of the scaled pixel x, y, pixels below the screen are black.
+func (dev *device) average(x, y int) uint32 {
+	var r, g, b, n uint32
+	for py := y * dev.scale; py < (y+1)*dev.scale && py < dev.height; py++ {
+		for px := x * dev.scale; px < (x+1)*dev.scale && px < dev.width; px++ {
+			c := dev.pixels[py*dev.width+px]
+			r += c >> 16
+			g += (c >> 8) & 0xFF
+			b += c & 0xFF
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return (r/n)<<16 | (g/n)<<8 | b/n
+}
+
+// Update redraws the cells that changed since the last Update and returns their number.
+// The cells are only taken as drawn when writing them to the terminal succeeds, so the
+// cells of a failed Update are redrawn by the next one.
+func (dev *device) Update() int {
+	dev.err = nil
+	columns, rows := dev.cells()
+	w := bufio.NewWriter(dev.out)
+	front := make([]uint32, 2*columns*rows)
+	if dev.drawn {
+		copy(front, dev.front)
+	} else {
+		w.WriteString("\x1b[2J")
+	}
+	changed := 0
+	fg, bg := -1, -1
+	for row := 0; row < rows; row++ {
+		next := -1
+		for column := 0; column < columns; column++ {
+			upper := dev.average(column, 2*row)
+			lower := dev.average(column, 2*row+1)
+			i := 2 * (row*columns + column)
+			if dev.drawn && front[i] == upper && front[i+1] == lower {
+				continue
+			}
+			front[i] = upper
+			front[i+1] = lower
+			changed++
+			if next != column {
+				fmt.Fprintf(w, "\x1b[%d;%dH", row+1, column+1)
+			}
+			if int(upper) != fg {
+				fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm", upper>>16, (upper>>8)&0xFF, upper&0xFF)
+				fg = int(upper)
+			}
+			if int(lower) != bg {
+				fmt.Fprintf(w, "\x1b[48;2;%d;%d;%dm", lower>>16, (lower>>8)&0xFF, lower&0xFF)
+				bg = int(lower)
+			}
+			w.WriteString(UPPER_HALF_BLOCK)
+			next = column + 1
+		}
+	}
+	if changed > 0 || !dev.drawn {
+		fmt.Fprintf(w, "\x1b[0m\x1b[%d;1H", rows+1)
+	}
+	if err := w.Flush(); err != nil {
+		dev.err = err
+		return changed
+	}
+	dev.front = front
+	dev.drawn = true
+	return changed
+}
+
+// Err returns the error writing to the terminal in the last Update.
+func (dev *device) Err() error {
+	return dev.err
+}
@@ -0,0 +1,99 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	var out bytes.Buffer
+	dev := NewTerminalDevice(2, 2, &out)
+	dev.Pixel(0, 0, 0xFF0000)
+	dev.Pixel(0, 1, 0x0000FF)
+	if changed := dev.Update(); changed != 2 {
+		t.Errorf("first Update changed %d cells, want 2", changed)
+	}
+	want := "\x1b[2J\x1b[1;1H" +
+		"\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m" + UPPER_HALF_BLOCK +
+		"\x1b[38;2;0;0;0m\x1b[48;2;0;0;0m" + UPPER_HALF_BLOCK +
+		"\x1b[0m\x1b[2;1H"
+	if got := out.String(); got != want {
+		t.Errorf("first Update wrote %q, want %q", got, want)
+	}
+
+	out.Reset()
+	if changed := dev.Update(); changed != 0 || out.Len() != 0 {
+		t.Errorf("Update without changes changed %d cells and wrote %q", changed, out.String())
+	}
+
+	out.Reset()
+	dev.Pixel(1, 1, 0x00FF00)
+	if changed := dev.Update(); changed != 1 {
+		t.Errorf("Update changed %d cells, want 1", changed)
+	}
+	want = "\x1b[1;2H\x1b[38;2;0;0;0m\x1b[48;2;0;255;0m" + UPPER_HALF_BLOCK + "\x1b[0m\x1b[2;1H"
+	if got := out.String(); got != want {
+		t.Errorf("Update wrote %q, want %q", got, want)
+	}
+}
+
+func TestSetTerminalSize(t *testing.T) {
+	var out bytes.Buffer
+	dev := NewTerminalDevice(4, 4, &out)
+	dev.SetTerminalSize(2, 2)
+	if scale := dev.Scale(); scale != 2 {
+		t.Fatalf("scale %d, want 2", scale)
+	}
+	dev.Pixel(0, 0, 0xFFFFFF)
+	dev.Pixel(1, 1, 0xFFFFFF)
+	dev.Pixel(2, 2, 0xFFFFFF)
+	dev.Pixel(3, 2, 0xFFFFFF)
+	dev.Pixel(2, 3, 0xFFFFFF)
+	dev.Pixel(3, 3, 0xFFFFFF)
+	if changed := dev.Update(); changed != 2 {
+		t.Errorf("Update changed %d cells, want 2", changed)
+	}
+	want := "\x1b[2J\x1b[1;1H" +
+		"\x1b[38;2;127;127;127m\x1b[48;2;0;0;0m" + UPPER_HALF_BLOCK +
+		"\x1b[38;2;0;0;0m\x1b[48;2;255;255;255m" + UPPER_HALF_BLOCK +
+		"\x1b[0m\x1b[2;1H"
+	if got := out.String(); got != want {
+		t.Errorf("Update wrote %q, want %q", got, want)
+	}
+}
+
+// failingWriter fails while fail is set.
+type failingWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("write failed")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestErrors(t *testing.T) {
+	out := &failingWriter{fail: true}
+	dev := NewTerminalDevice(2, 2, out)
+	dev.Pixel(0, 0, 0xFF0000)
+	dev.Update()
+	if dev.Err() == nil {
+		t.Error("the write error is not reported")
+	}
+
+	out.fail = false
+	if changed := dev.Update(); changed != 2 || dev.Err() != nil {
+		t.Errorf("Update after the terminal recovered changed %d cells and reports %v", changed, dev.Err())
+	}
+	if !strings.Contains(out.String(), "\x1b[38;2;255;0;0m") {
+		t.Errorf("the cells of the failed Update are not redrawn: %q", out.String())
+	}
+	if dev.Update(); dev.Err() != nil {
+		t.Errorf("Update without changes reports %v", dev.Err())
+	}
+}
@@ -0,0 +1,110 @@
+package drawings
+
+import (
+	"math"
+	"testing"
+)
+
+// inArcReference tells with math.Atan2 if the pixel is on the arc going from start to end in
+// increasing angles, or in decreasing angles when ccw. near is set when the pixel is too
+// close to an end of the arc to tell.
+func inArcReference(px, py, xc, yc, start, end float64, ccw bool) (in, near bool) {
+	if math.Abs(end-start) >= 2*math.Pi {
+		return true, false
+	}
+	if ccw {
+		start, end = end, start
+	}
+	a := math.Atan2(py-yc, px-xc)
+	for _, e := range []float64{start, end} {
+		if math.Abs(math.Remainder(a-e, 2*math.Pi)) < 1e-9 {
+			return false, true
+		}
+	}
+	sweep := end - start
+	if sweep < 0 {
+		sweep += 2 * math.Pi
+	}
+	k := math.Ceil((start - a) / (2 * math.Pi))
+	return a+2*math.Pi*k <= start+sweep, false
+}
+
+func FuzzArc(f *testing.F) {
+	f.Add(0.0, math.Pi/2, false, 10.0, 0.0, 0.0)
+	f.Add(-math.Pi/3, math.Pi/4, false, 7.5, 0.5, 0.3)
+	f.Add(-5.0, -2.0, false, 9.0, 0.2, 0.7)
+	f.Add(3.0, 1.0, false, 10.3, 0.0, 0.5)
+	f.Add(0.5, 0.5+2*math.Pi, false, 6.0, 0.0, 0.0)
+	f.Add(-1.0, 8.0, false, 6.0, 0.1, 0.1)
+	f.Add(1.0, -9.0, true, 8.0, 0.4, 0.0)
+	f.Add(0.0, math.Pi/2, true, 10.0, 0.0, 0.0)
+	f.Add(-7.0, -0.5, true, 4.0, 0.5, 0.5)
+	f.Add(100.0, 101.5, true, 11.0, 0.25, 0.75)
+	f.Fuzz(func(t *testing.T, start, end float64, ccw bool, radius, fx, fy float64) {
+		for _, v := range []float64{start, end, radius, fx, fy} {
+			if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > 1e6 {
+				return
+			}
+		}
+		radius = math.Mod(math.Abs(radius), 12)
+		xc := 15 + math.Mod(fx, 1)
+		yc := 15 + math.Mod(fy, 1)
+		direction := CLOCKWISE
+		if ccw {
+			direction = COUNTER_CLOCKWISE
+		}
+
+		dev := newCountingDevice(32, 32)
+		s := NewSketcher(dev, 0)
+		circle := dev.drawn(func() { s.Circle(xc, yc, radius, 1) })
+		arc := dev.drawn(func() { s.DirectedArc(xc, yc, radius, start, end, direction, 1) })
+		if !ccw {
+			if plain := dev.drawn(func() { s.Arc(xc, yc, radius, start, end, 1) }); !equalCounts(plain, arc) {
+				t.Fatalf("Arc and clockwise DirectedArc differ")
+			}
+		}
+		for i := range circle {
+			x, y := float64(i%dev.width), float64(i/dev.width)
+			if circle[i] == 0 {
+				if arc[i] > 0 {
+					t.Fatalf("arc pixel %v, %v is not on the circle", x, y)
+				}
+				continue
+			}
+			in, near := inArcReference(x, y, xc, yc, start, end, ccw)
+			if !near && in != (arc[i] > 0) {
+				t.Fatalf("pixel %v, %v at %.6f: drawn %v, want %v", x, y, math.Atan2(y-yc, x-xc), arc[i] > 0, in)
+			}
+		}
+	})
+}
+
+func equalCounts(a, b []int) bool {
+	for i := range a {
+		if (a[i] > 0) != (b[i] > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIsAngleInArc(t *testing.T) {
+	tests := []struct {
+		angle, start, end float64
+		want              bool
+	}{
+		{0.5, 0, 1, true},
+		{1.5, 0, 1, false},
+		{-0.5, -1, 0, true},
+		{0.5, -1, 0, false},
+		{-3, 3, -2.5, true},
+		{0, 3, -2.5, false},
+		{0.5 + 4*math.Pi, 0, 1, true},
+		{2, 1, 1 + 3*math.Pi, true},
+	}
+	for _, test := range tests {
+		if got := isAngleInArc(test.angle, test.start, test.end); got != test.want {
+			t.Errorf("isAngleInArc(%v, %v, %v) = %v, want %v", test.angle, test.start, test.end, got, test.want)
+		}
+	}
+}
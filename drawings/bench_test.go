@@ -0,0 +1,164 @@
+package drawings_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/devices/profiler"
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/internal/null"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+const (
+	SCREEN_WIDTH  = 320
+	SCREEN_HEIGHT = 240
+)
+
+var rotations = []int{drawings.ROTATION_0, drawings.ROTATION_90, drawings.ROTATION_180, drawings.ROTATION_270}
+
+var benchPoints = []drawings.Point{{X: 10, Y: 200}, {X: 60, Y: 80}, {X: 120, Y: 150}, {X: 180, Y: 40}, {X: 240, Y: 120}, {X: 310, Y: 60}}
+
+var benchPath = drawings.NewPath().
+	MoveTo(60, 120).ArcTo(100, 100, 0, true, true, 260, 120).ArcTo(100, 100, 0, true, true, 60, 120).Close().
+	MoveTo(110, 120).QuadTo(160, 20, 210, 120).CubicTo(210, 200, 110, 200, 110, 120).Close()
+
+// benchmark draws with one Sketcher method. maxOverdraw is the largest part of its pixels
+// the method may write more than once in one call.
+type benchmark struct {
+	name        string
+	draw        func(drawings.Sketcher)
+	maxOverdraw float64
+}
+
+var benchmarks = []benchmark{
+	{"Update", func(s drawings.Sketcher) { s.Update() }, 0},
+	{"Err", func(s drawings.Sketcher) { s.Err() }, 0},
+	{"DirtyRegions", func(s drawings.Sketcher) { s.DirtyRegions() }, 0},
+	{"SetRotation", func(s drawings.Sketcher) { s.SetRotation(drawings.ROTATION_0) }, 0},
+	{"ScreenWidth", func(s drawings.Sketcher) { s.ScreenWidth() }, 0},
+	{"ScreenHeight", func(s drawings.Sketcher) { s.ScreenHeight() }, 0},
+	{"Clear", func(s drawings.Sketcher) { s.Clear(0) }, 0},
+	{"ClearArea", func(s drawings.Sketcher) { s.ClearArea(10, 10, 310, 230, 1) }, 0},
+	{"ClearAreaPerPixel", clearAreaPerPixel, 0},
+	{"Pixel", func(s drawings.Sketcher) { s.Pixel(160, 120, 1) }, 0},
+	{"Line", func(s drawings.Sketcher) { s.Line(10, 20, 300, 220, 1) }, 0},
+	{"Arc", func(s drawings.Sketcher) { s.Arc(160, 120, 100, 0.3, 4, 1) }, 0.025},
+	{"DirectedArc", func(s drawings.Sketcher) { s.DirectedArc(160, 120, 100, 0.3, 4, drawings.COUNTER_CLOCKWISE, 1) }, 0.025},
+	{"ThickArc", func(s drawings.Sketcher) { s.ThickArc(160, 120, 100, 0.3, 4, 10, drawings.CENTER_WIDTH, 1) }, 0},
+	{"Circle", func(s drawings.Sketcher) { s.Circle(160, 120, 100, 1) }, 0.025},
+	{"Rectangle", func(s drawings.Sketcher) { s.Rectangle(10, 10, 310, 230, 1) }, 0.005},
+	{"FillCircle", func(s drawings.Sketcher) { s.FillCircle(160, 120, 100, 1) }, 0},
+	{"FillCirclePerLine", fillCircleByLines, 0.15},
+	{"ThickCircle", func(s drawings.Sketcher) { s.ThickCircle(160, 120, 100, 10, drawings.CENTER_WIDTH, 1) }, 0},
+	{"FillRectangle", func(s drawings.Sketcher) { s.FillRectangle(10, 10, 310, 230, 1) }, 0},
+	{"FillRectanglePerLine", fillRectangleByLines, 0},
+	{"FillRectangleGradient", func(s drawings.Sketcher) { s.FillRectangleGradient(10, 10, 310, 230, 0, 0xFFFFFF, false) }, 0},
+	{"ThickRectangle", func(s drawings.Sketcher) { s.ThickRectangle(20, 20, 300, 220, 10, drawings.CENTER_WIDTH, 1) }, 0.005},
+	{"Polyline", func(s drawings.Sketcher) { s.Polyline(benchPoints, 1) }, 0.01},
+	{"ThickPolyline", func(s drawings.Sketcher) { s.ThickPolyline(benchPoints, 6, 1) }, 0},
+	{"FillPolygon", func(s drawings.Sketcher) { s.FillPolygon(benchPoints, 1) }, 0},
+	{"QuadBezier", func(s drawings.Sketcher) { s.QuadBezier(10, 230, 160, 0, 310, 230, 1) }, 0.1},
+	{"CubicBezier", func(s drawings.Sketcher) { s.CubicBezier(10, 10, 100, 300, 220, -60, 310, 200, 1) }, 0.15},
+	{"Spline", func(s drawings.Sketcher) { s.Spline(benchPoints, 1) }, 0.15},
+	{"ThickQuadBezier", func(s drawings.Sketcher) { s.ThickQuadBezier(10, 230, 160, 0, 310, 230, 6, 1) }, 0},
+	{"ThickCubicBezier", func(s drawings.Sketcher) { s.ThickCubicBezier(10, 10, 100, 300, 220, -60, 310, 200, 6, 1) }, 0},
+	{"ThickSpline", func(s drawings.Sketcher) { s.ThickSpline(benchPoints, 6, 1) }, 0},
+	{"FillSpline", func(s drawings.Sketcher) { s.FillSpline(benchPoints, 1) }, 0},
+	{"StrokePath", func(s drawings.Sketcher) { s.StrokePath(benchPath, 4, 1) }, 0},
+	{"FillPath", func(s drawings.Sketcher) { s.FillPath(benchPath, drawings.EVEN_ODD, 1) }, 0},
+	{"SetFont", func(s drawings.Sketcher) { s.SetFont(fonts.FreeSans12pt7b) }, 0},
+	{"Write", func(s drawings.Sketcher) { s.Write("Hello World", 1) }, 0},
+	{"WriteScaled", func(s drawings.Sketcher) { s.WriteScaled("Hello", 2.5, 2.5, 1) }, 0},
+	{"WriteRotated", func(s drawings.Sketcher) { s.WriteRotated("Hello World", 0.5, 1) }, 0},
+	{"WriteRotatedScaled", func(s drawings.Sketcher) { s.WriteRotatedScaled("Hello", 2.5, 2.5, 0.5, 1) }, 0},
+	{"MoveCursor", func(s drawings.Sketcher) { s.MoveCursor(10, 10) }, 0},
+	{"GetTextArea", func(s drawings.Sketcher) { s.GetTextArea(10, 100, "Hello World", 1, 1) }, 0},
+	{"GetRotatedTextArea", func(s drawings.Sketcher) { s.GetRotatedTextArea(10, 100, "Hello World", 1, 1, 0.5) }, 0},
+	{"GetTextScaleToFit", func(s drawings.Sketcher) { s.GetTextScaleToFit("Hello World", 300, 100) }, 0},
+	{"SetFontSampling", func(s drawings.Sketcher) { s.SetFontSampling(drawings.NEAREST_SAMPLING) }, 0},
+	{"SetFontSmoothing", func(s drawings.Sketcher) { s.SetFontSmoothing(false, nil) }, 0},
+	{"SetLetterSpacing", func(s drawings.Sketcher) { s.SetLetterSpacing(0) }, 0},
+	{"SetWordSpacing", func(s drawings.Sketcher) { s.SetWordSpacing(0) }, 0},
+	{"SetKerning", func(s drawings.Sketcher) { s.SetKerning(nil) }, 0},
+}
+
+// clearAreaPerPixel, fillRectangleByLines and fillCircleByLines draw the same shapes the way
+// the sketcher did before it had a span rasteriser, as a reference for the speedup.
+func clearAreaPerPixel(s drawings.Sketcher) {
+	for x := float64(10); x <= 310; x++ {
+		for y := float64(10); y <= 230; y++ {
+			s.Pixel(x, y, 1)
+		}
+	}
+}
+
+func fillRectangleByLines(s drawings.Sketcher) {
+	for y := float64(10); y < 230; y++ {
+		s.Line(10, y, 310, y, 1)
+	}
+}
+
+func fillCircleByLines(s drawings.Sketcher) {
+	const X, Y, RADIUS float64 = 160, 120, 100
+	for dr := float64(0); dr <= math.Ceil(RADIUS*0.707); dr += 1 {
+		d := math.Sqrt(RADIUS*RADIUS - dr*dr)
+		s.Line(X+d, Y+dr, X-d, Y+dr, 1)
+		s.Line(X+d, Y-dr, X-d, Y-dr, 1)
+		s.Line(X+dr, Y+d, X-dr, Y+d, 1)
+		s.Line(X+dr, Y-d, X-dr, Y-d, 1)
+	}
+}
+
+func newProfiledSketcher(rotation int) (drawings.Sketcher, interface {
+	Begin(section string)
+	Stats(section string) profiler.Stats
+}) {
+	dev := profiler.NewProfilerDevice(null.NewNullDevice(SCREEN_WIDTH, SCREEN_HEIGHT))
+	sketcher := drawings.NewSketcher(dev, 0)
+	sketcher.SetFont(fonts.FreeSans12pt7b)
+	sketcher.SetRotation(float64(rotation))
+	return sketcher, dev
+}
+
+// BenchmarkSketcher runs every Sketcher method in the four screen rotations and reports the
+// pixel writes of a call, e.g. go test -bench Sketcher/FillCircle ./drawings
+func BenchmarkSketcher(b *testing.B) {
+	for _, bm := range benchmarks {
+		for _, rotation := range rotations {
+			b.Run(fmt.Sprintf("%s/rotation=%d", bm.name, rotation*90), func(b *testing.B) {
+				sketcher, dev := newProfiledSketcher(rotation)
+				for i := 0; i < b.N; i++ {
+					dev.Begin(bm.name)
+					sketcher.MoveCursor(20, 120)
+					bm.draw(sketcher)
+				}
+				stats := dev.Stats(bm.name)
+				n := float64(b.N)
+				b.ReportMetric(float64(stats.Pixels)/n, "pixels/op")
+				b.ReportMetric(float64(stats.Duplicates)/n, "dup/op")
+				b.ReportMetric(float64(stats.OutOfBounds)/n, "out/op")
+			})
+		}
+	}
+}
+
+// TestOverdraw checks with the profiler that no method writes more of its pixels twice than
+// it is allowed to, in every rotation.
+func TestOverdraw(t *testing.T) {
+	for _, bm := range benchmarks {
+		for _, rotation := range rotations {
+			sketcher, dev := newProfiledSketcher(rotation)
+			dev.Begin(bm.name)
+			sketcher.MoveCursor(20, 120)
+			bm.draw(sketcher)
+			stats := dev.Stats(bm.name)
+			t.Logf("%s rotation %d: %d pixels, %d duplicates", bm.name, rotation*90, stats.Pixels, stats.Duplicates)
+			if stats.Pixels > 0 && float64(stats.Duplicates)/float64(stats.Pixels) > bm.maxOverdraw {
+				t.Errorf("%s in rotation %d overdraws %.1f%% of its pixels, at most %.1f%% allowed",
+					bm.name, rotation*90, 100*float64(stats.Duplicates)/float64(stats.Pixels), 100*bm.maxOverdraw)
+			}
+		}
+	}
+}
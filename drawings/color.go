@@ -0,0 +1,151 @@
+package drawings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedColor = errors.New("unsupported color")
+
+// Color is an RGB colour with 8 bits per channel. It implements image/color.Color and can be
+// drawn on devices that do not take it with a colour converter device.
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+func RGB(r, g, b uint8) Color {
+	return Color{R: r, G: g, B: b}
+}
+
+// Grey returns the colour with the same level in every channel.
+func Grey(level uint8) Color {
+	return Color{R: level, G: level, B: level}
+}
+
+var namedColors = map[string]Color{
+	"black":   {0x00, 0x00, 0x00},
+	"white":   {0xFF, 0xFF, 0xFF},
+	"red":     {0xFF, 0x00, 0x00},
+	"green":   {0x00, 0x80, 0x00},
+	"lime":    {0x00, 0xFF, 0x00},
+	"blue":    {0x00, 0x00, 0xFF},
+	"yellow":  {0xFF, 0xFF, 0x00},
+	"cyan":    {0x00, 0xFF, 0xFF},
+	"aqua":    {0x00, 0xFF, 0xFF},
+	"magenta": {0xFF, 0x00, 0xFF},
+	"fuchsia": {0xFF, 0x00, 0xFF},
+	"gray":    {0x80, 0x80, 0x80},
+	"grey":    {0x80, 0x80, 0x80},
+	"silver":  {0xC0, 0xC0, 0xC0},
+	"maroon":  {0x80, 0x00, 0x00},
+	"olive":   {0x80, 0x80, 0x00},
+	"navy":    {0x00, 0x00, 0x80},
+	"purple":  {0x80, 0x00, 0x80},
+	"teal":    {0x00, 0x80, 0x80},
+	"orange":  {0xFF, 0xA5, 0x00},
+}
+
+// ParseColor reads a colour written as #rgb, #rrggbb or a CSS colour name such as "navy".
+func ParseColor(s string) (Color, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[value]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err == nil && len(hex) == 6 {
+			return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+		}
+	}
+	return Color{}, fmt.Errorf("invalid color %q", s)
+}
+
+// ToColor converts the colours used with the drawing functions: Color, integers taken as
+// 0xRRGGBB, strings as in ParseColor and image/color colours. Other types with an uint32
+// value such as the drivers-go RGB888 are taken as 0xRRGGBB and with an uint16 value such
+// as the drivers-go RGB565 as RGB565.
+func ToColor(c any) (Color, error) {
+	switch v := c.(type) {
+	case Color:
+		return v, nil
+	case int:
+		return fromRGB888(uint32(v)), nil
+	case uint32:
+		return fromRGB888(v), nil
+	case uint16:
+		return fromRGB565(v), nil
+	case string:
+		return ParseColor(v)
+	case color.Color:
+		r, g, b, _ := v.RGBA()
+		return Color{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}, nil
+	}
+	if c != nil {
+		switch v := reflect.ValueOf(c); v.Kind() {
+		case reflect.Uint32:
+			return fromRGB888(uint32(v.Uint())), nil
+		case reflect.Uint16:
+			return fromRGB565(uint16(v.Uint())), nil
+		}
+	}
+	return Color{}, fmt.Errorf("%w %T", ErrUnsupportedColor, c)
+}
+
+func fromRGB888(v uint32) Color {
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}
+}
+
+func fromRGB565(v uint16) Color {
+	r := uint8(v>>11) & 0x1F
+	g := uint8(v>>5) & 0x3F
+	b := uint8(v) & 0x1F
+	return Color{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2}
+}
+
+// RGB888 returns the colour as 0xRRGGBB.
+func (c Color) RGB888() uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
+
+// RGB565 returns the colour with 5 bits of red, 6 of green and 5 of blue.
+func (c Color) RGB565() uint16 {
+	return uint16(c.R>>3)<<11 | uint16(c.G>>2)<<5 | uint16(c.B>>3)
+}
+
+// Grey returns the luminance of the colour with the Rec. 601 weights.
+func (c Color) Grey() uint8 {
+	return uint8((299*uint32(c.R) + 587*uint32(c.G) + 114*uint32(c.B)) / 1000)
+}
+
+// RGBA implements image/color.Color, the colour is opaque.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	r = uint32(c.R) * 0x101
+	g = uint32(c.G) * 0x101
+	b = uint32(c.B) * 0x101
+	return r, g, b, 0xFFFF
+}
+
+// String returns the colour as #rrggbb.
+func (c Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Mix returns the colour a fraction t of the way from c to other, t is clamped to 0..1.
+func (c Color) Mix(other Color, t float64) Color {
+	t = math.Max(0, math.Min(1, t))
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return Color{mix(c.R, other.R), mix(c.G, other.G), mix(c.B, other.B)}
+}
@@ -0,0 +1,51 @@
+package drawings
+
+import (
+	"image/color"
+	"testing"
+)
+
+// rgb888 and rgb565 stand in for the colour types of the drivers.
+type (
+	rgb888 uint32
+	rgb565 uint16
+)
+
+func TestToColor(t *testing.T) {
+	want := Color{R: 0xFF, G: 0x82, B: 0x08}
+	tests := []any{
+		want,
+		0xFF8208,
+		uint32(0xFF8208),
+		rgb888(0xFF8208),
+		want.RGB565(),
+		rgb565(want.RGB565()),
+		"#ff8208",
+		color.RGBA{R: 0xFF, G: 0x82, B: 0x08, A: 0xFF},
+	}
+	for _, c := range tests {
+		got, err := ToColor(c)
+		if err != nil {
+			t.Errorf("%T %v: %v", c, c, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%T %v is %v, want %v", c, c, got, want)
+		}
+	}
+	for _, c := range []any{nil, 1.5, int64(1), "nocolor"} {
+		if _, err := ToColor(c); err == nil {
+			t.Errorf("%T %v converted", c, c)
+		}
+	}
+}
+
+func TestRGB(t *testing.T) {
+	c := Color{R: 0x12, G: 0x34, B: 0x56}
+	if got := c.RGB888(); got != 0x123456 {
+		t.Errorf("RGB888 = %#x", got)
+	}
+	if got := c.RGB565(); got != 0x11AA {
+		t.Errorf("RGB565 = %#x", got)
+	}
+}
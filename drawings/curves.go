@@ -0,0 +1,268 @@
+package drawings
+
+import (
+	"math"
+	"sort"
+)
+
+// FLATNESS is the largest distance, in pixels, between a curve and the lines drawn for it.
+const FLATNESS float64 = 0.25
+
+const maxSubdivisions = 16
+
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p Point) sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+func (p Point) scale(s float64) Point {
+	return Point{p.X * s, p.Y * s}
+}
+
+func midPoint(p, q Point) Point {
+	return Point{(p.X + q.X) / 2, (p.Y + q.Y) / 2}
+}
+
+// distanceToLine returns the distance of p from the line through a and b.
+func distanceToLine(p, a, b Point) float64 {
+	d := b.sub(a)
+	l := math.Hypot(d.X, d.Y)
+	if l == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	return math.Abs(d.X*(a.Y-p.Y)-d.Y*(a.X-p.X)) / l
+}
+
+// flattenQuadBezier appends the end points of the lines approximating the curve, the start point is not added.
+func flattenQuadBezier(points []Point, p0, p1, p2 Point, depth int) []Point {
+	if depth >= maxSubdivisions || distanceToLine(p1, p0, p2) <= FLATNESS {
+		return append(points, p2)
+	}
+	// de Casteljau subdivision
+	p01 := midPoint(p0, p1)
+	p12 := midPoint(p1, p2)
+	m := midPoint(p01, p12)
+	points = flattenQuadBezier(points, p0, p01, m, depth+1)
+	return flattenQuadBezier(points, m, p12, p2, depth+1)
+}
+
+func flattenCubicBezier(points []Point, p0, p1, p2, p3 Point, depth int) []Point {
+	if depth >= maxSubdivisions || math.Max(distanceToLine(p1, p0, p3), distanceToLine(p2, p0, p3)) <= FLATNESS {
+		return append(points, p3)
+	}
+	p01 := midPoint(p0, p1)
+	p12 := midPoint(p1, p2)
+	p23 := midPoint(p2, p3)
+	p012 := midPoint(p01, p12)
+	p123 := midPoint(p12, p23)
+	m := midPoint(p012, p123)
+	points = flattenCubicBezier(points, p0, p01, p012, m, depth+1)
+	return flattenCubicBezier(points, m, p123, p23, p3, depth+1)
+}
+
+// flattenSpline approximates the Catmull-Rom spline through the points with lines.
+func flattenSpline(points []Point, closed bool) []Point {
+	n := len(points)
+	if n < 3 {
+		return append([]Point{}, points...)
+	}
+	at := func(i int) Point {
+		if closed {
+			return points[(i+n)%n]
+		}
+		if i < 0 {
+			return points[0]
+		}
+		if i >= n {
+			return points[n-1]
+		}
+		return points[i]
+	}
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	flat := []Point{points[0]}
+	for i := 0; i < segments; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		c1 := p1.add(p2.sub(p0).scale(1.0 / 6))
+		c2 := p2.sub(p3.sub(p1).scale(1.0 / 6))
+		flat = flattenCubicBezier(flat, p1, c1, c2, p2, 0)
+	}
+	return flat
+}
+
+func (dev *sketcher) Polyline(points []Point, color any) {
+	defer dev.commitDirty()
+	if len(points) == 1 {
+		dev.rotatedPixel(points[0].X, points[0].Y, color)
+	}
+	for i := 1; i < len(points); i++ {
+		dev.Line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, color)
+	}
+}
+
+// ThickPolyline draws the lines width wide with round joins and ends.
+func (dev *sketcher) ThickPolyline(points []Point, width float64, color any) {
+	defer dev.commitDirty()
+	dev.fillPolygons(strokePolygons(points, false, width), NON_ZERO, color)
+}
+
+// FillPolygon fills the closed polygon, overlapping parts are filled with the non-zero winding rule.
+func (dev *sketcher) FillPolygon(points []Point, color any) {
+	defer dev.commitDirty()
+	dev.fillPolygons([][]Point{points}, NON_ZERO, color)
+}
+
+func (dev *sketcher) QuadBezier(x1, y1, cx, cy, x2, y2 float64, color any) {
+	dev.Polyline(flattenQuadBezier([]Point{{x1, y1}}, Point{x1, y1}, Point{cx, cy}, Point{x2, y2}, 0), color)
+}
+
+func (dev *sketcher) CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color any) {
+	dev.Polyline(flattenCubicBezier([]Point{{x1, y1}}, Point{x1, y1}, Point{cx1, cy1}, Point{cx2, cy2}, Point{x2, y2}, 0), color)
+}
+
+// Spline draws a Catmull-Rom spline going through all the points.
+func (dev *sketcher) Spline(points []Point, color any) {
+	dev.Polyline(flattenSpline(points, false), color)
+}
+
+func (dev *sketcher) ThickQuadBezier(x1, y1, cx, cy, x2, y2 float64, width float64, color any) {
+	dev.ThickPolyline(flattenQuadBezier([]Point{{x1, y1}}, Point{x1, y1}, Point{cx, cy}, Point{x2, y2}, 0), width, color)
+}
+
+func (dev *sketcher) ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, width float64, color any) {
+	dev.ThickPolyline(flattenCubicBezier([]Point{{x1, y1}}, Point{x1, y1}, Point{cx1, cy1}, Point{cx2, cy2}, Point{x2, y2}, 0), width, color)
+}
+
+func (dev *sketcher) ThickSpline(points []Point, width float64, color any) {
+	dev.ThickPolyline(flattenSpline(points, false), width, color)
+}
+
+// FillSpline fills the area inside the closed Catmull-Rom spline through the points.
+func (dev *sketcher) FillSpline(points []Point, color any) {
+	dev.FillPolygon(flattenSpline(points, true), color)
+}
+
+// strokePolygons covers a polyline of the given width with one rectangle per line and a
+// disc at every point. All polygons turn the same way so a non-zero fill merges them.
+func strokePolygons(points []Point, closed bool, width float64) [][]Point {
+	polygons := make([][]Point, 0, len(points)*2)
+	hw := width / 2
+	if hw <= 0 {
+		return polygons
+	}
+	n := len(points)
+	lines := n - 1
+	if closed && n > 2 {
+		lines = n
+	}
+	for i := 0; i < lines; i++ {
+		p := points[i]
+		q := points[(i+1)%n]
+		d := q.sub(p)
+		l := math.Hypot(d.X, d.Y)
+		if l == 0 {
+			continue
+		}
+		normal := Point{-d.Y / l * hw, d.X / l * hw}
+		polygons = append(polygons, []Point{p.add(normal), q.add(normal), q.sub(normal), p.sub(normal)})
+	}
+	segments := int(math.Ceil(math.Pi / math.Acos(math.Max(-1, 1-FLATNESS/math.Max(hw, FLATNESS)))))
+	if segments < 8 {
+		segments = 8
+	}
+	for _, p := range points {
+		disc := make([]Point, segments)
+		for i := 0; i < segments; i++ {
+			sin, cos := math.Sincos(-float64(i) * DEG360 / float64(segments))
+			disc[i] = Point{p.X + hw*cos, p.Y + hw*sin}
+		}
+		polygons = append(polygons, disc)
+	}
+	return polygons
+}
+
+type polygonEdge struct {
+	x0, y0, x1, y1 float64
+	winding        int
+}
+
+type edgeCrossing struct {
+	x       float64
+	winding int
+}
+
+// fillPolygons fills the pixels whose centres are inside the polygons by the fill rule.
+func (dev *sketcher) fillPolygons(polygons [][]Point, rule FillRule, color any) {
+	edges := make([]polygonEdge, 0)
+	ymin := math.Inf(1)
+	ymax := math.Inf(-1)
+	for _, polygon := range polygons {
+		n := len(polygon)
+		for i := 0; i < n; i++ {
+			p := polygon[i]
+			q := polygon[(i+1)%n]
+			ymin = math.Min(ymin, p.Y)
+			ymax = math.Max(ymax, p.Y)
+			if p.Y == q.Y {
+				continue
+			}
+			if p.Y < q.Y {
+				edges = append(edges, polygonEdge{p.X, p.Y, q.X, q.Y, 1})
+			} else {
+				edges = append(edges, polygonEdge{q.X, q.Y, p.X, p.Y, -1})
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+
+	crossings := make([]edgeCrossing, 0)
+	ys, ye := clipRange(math.Ceil(ymin), math.Floor(ymax), dev.ScreenHeight())
+	for y := float64(ys); y <= float64(ye); y++ {
+		crossings = crossings[:0]
+		for _, e := range edges {
+			if y < e.y0 || y >= e.y1 {
+				continue
+			}
+			x := e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+			crossings = append(crossings, edgeCrossing{x, e.winding})
+		}
+		sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+		winding := 0
+		start := 0.0
+		last := math.Inf(-1)
+		for _, c := range crossings {
+			inside := winding != 0
+			if rule == NON_ZERO {
+				winding += c.winding
+			} else {
+				winding ^= 1
+			}
+			if !inside && winding != 0 {
+				start = c.x
+			} else if inside && winding == 0 {
+				x1 := math.Ceil(start)
+				if x1 <= last {
+					x1 = last + 1
+				}
+				x2 := math.Floor(c.x)
+				if x1 <= x2 {
+					if xs, xe := clipRange(x1, x2, dev.ScreenWidth()); xs <= xe {
+						dev.hspan(xs, xe, int(y), color)
+					}
+					last = x2
+				}
+			}
+		}
+	}
+}
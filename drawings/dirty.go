@@ -0,0 +1,96 @@
+package drawings
+
+// Rect is an area of the device in device pixels, both corners included.
+type Rect struct {
+	X1, Y1, X2, Y2 int
+}
+
+// regionUpdater is implemented by devices that can push part of their screen.
+type regionUpdater interface {
+	UpdateRegion(x1, y1, x2, y2 int) int
+}
+
+func (r Rect) touches(o Rect) bool {
+	return r.X1 <= o.X2+1 && o.X1 <= r.X2+1 && r.Y1 <= o.Y2+1 && o.Y1 <= r.Y2+1
+}
+
+func (r Rect) union(o Rect) Rect {
+	if o.X1 < r.X1 {
+		r.X1 = o.X1
+	}
+	if o.Y1 < r.Y1 {
+		r.Y1 = o.Y1
+	}
+	if o.X2 > r.X2 {
+		r.X2 = o.X2
+	}
+	if o.Y2 > r.Y2 {
+		r.Y2 = o.Y2
+	}
+	return r
+}
+
+// markDirty extends the area changed by the primitive being drawn.
+func (d *sketcher) markDirty(x, y int) {
+	if x < 0 || y < 0 || x >= d.pixeldev.ScreenWidth() || y >= d.pixeldev.ScreenHeight() {
+		return
+	}
+	if !d.hasPending {
+		d.pending = Rect{x, y, x, y}
+		d.hasPending = true
+		return
+	}
+	d.pending = d.pending.union(Rect{x, y, x, y})
+}
+
+// commitDirty adds the area of the last primitive to the dirty regions, merging the regions it overlaps.
+func (d *sketcher) commitDirty() {
+	if !d.hasPending {
+		return
+	}
+	region := d.pending
+	d.hasPending = false
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(d.dirty); i++ {
+			if region.touches(d.dirty[i]) {
+				region = region.union(d.dirty[i])
+				d.dirty = append(d.dirty[:i], d.dirty[i+1:]...)
+				merged = true
+				break
+			}
+		}
+	}
+	d.dirty = append(d.dirty, region)
+}
+
+func (d *sketcher) markAllDirty() {
+	d.hasPending = false
+	d.dirty = []Rect{{0, 0, d.pixeldev.ScreenWidth() - 1, d.pixeldev.ScreenHeight() - 1}}
+}
+
+// DirtyRegions returns the device areas changed since the last Update.
+func (d *sketcher) DirtyRegions() []Rect {
+	d.commitDirty()
+	regions := make([]Rect, len(d.dirty))
+	copy(regions, d.dirty)
+	return regions
+}
+
+// updateDevice pushes the dirty regions of a regionUpdater device, other devices and devices
+// without dirty regions get a full Update, e.g. for devices flushing on every Update.
+func (d *sketcher) updateDevice() int {
+	d.commitDirty()
+	dirty := make([]Rect, len(d.dirty))
+	copy(dirty, d.dirty)
+	d.dirty = d.dirty[:0]
+	dev, ok := d.pixeldev.(regionUpdater)
+	if !ok || len(dirty) == 0 {
+		return d.pixeldev.Update()
+	}
+	n := 0
+	for _, r := range dirty {
+		n += dev.UpdateRegion(r.X1, r.Y1, r.X2, r.Y2)
+	}
+	return n
+}
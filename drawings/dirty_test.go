@@ -0,0 +1,70 @@
+package drawings
+
+import (
+	"reflect"
+	"testing"
+)
+
+// regionDevice records the regions it is asked to update and the full updates.
+type regionDevice struct {
+	*countingDevice
+	regions  []Rect
+	updates  int
+	onUpdate func()
+}
+
+func (dev *regionDevice) UpdateRegion(x1, y1, x2, y2 int) int {
+	dev.regions = append(dev.regions, Rect{x1, y1, x2, y2})
+	if dev.onUpdate != nil {
+		dev.onUpdate()
+	}
+	return (x2 - x1 + 1) * (y2 - y1 + 1)
+}
+
+func (dev *regionDevice) Update() int {
+	dev.updates++
+	return 0
+}
+
+func TestUpdateRegions(t *testing.T) {
+	dev := &regionDevice{countingDevice: newCountingDevice(100, 100)}
+	s := NewSketcher(dev, 0)
+	s.FillRectangle(10, 10, 19, 14, 1)
+	s.FillRectangle(50, 50, 51, 51, 1)
+	if n := s.Update(); n != 50+4 {
+		t.Errorf("Update = %d, want 54 pixels", n)
+	}
+	want := []Rect{{10, 10, 19, 14}, {50, 50, 51, 51}}
+	if !reflect.DeepEqual(dev.regions, want) || dev.updates != 0 {
+		t.Errorf("regions %v and %d updates, want %v and none", dev.regions, dev.updates, want)
+	}
+
+	// nothing is dirty, the device still gets its Update
+	dev.regions = nil
+	s.Update()
+	if len(dev.regions) != 0 || dev.updates != 1 {
+		t.Errorf("regions %v and %d updates, want none and 1", dev.regions, dev.updates)
+	}
+}
+
+func TestUpdateRegionsDrawnWhileUpdating(t *testing.T) {
+	dev := &regionDevice{countingDevice: newCountingDevice(100, 100)}
+	s := NewSketcher(dev, 0)
+	// overlays drawn while the regions are pushed belongs to the next Update
+	dev.onUpdate = func() {
+		if len(dev.regions) == 1 {
+			s.FillRectangle(80, 80, 80, 80, 1)
+			s.FillRectangle(90, 10, 90, 10, 1)
+		}
+	}
+	s.FillRectangle(10, 10, 10, 10, 1)
+	s.FillRectangle(50, 50, 50, 50, 1)
+	s.Update()
+	want := []Rect{{10, 10, 10, 10}, {50, 50, 50, 50}}
+	if !reflect.DeepEqual(dev.regions, want) {
+		t.Errorf("regions %v, want %v", dev.regions, want)
+	}
+	if next := s.DirtyRegions(); !reflect.DeepEqual(next, []Rect{{80, 80, 80, 80}, {90, 10, 90, 10}}) {
+		t.Errorf("dirty regions after Update %v, want the overlays", next)
+	}
+}
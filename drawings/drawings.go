@@ -3,12 +3,15 @@ package drawings
 import (
 	"errors"
 	"math"
+	"reflect"
 
 	"github.com/marksaravi/fonts-go/fonts"
 )
 
 type WidthType int
 type FontType int
+type FontSampling int
+type ArcDirection int
 
 const (
 	DEG90  = math.Pi / 2
@@ -22,6 +25,7 @@ const (
 	OUTER_WIDTH    WidthType = 1
 	CENTER_WIDTH   WidthType = 2
 	MAX_FONT_SCALE int       = 10
+	MIN_FONT_SCALE float64   = 0.25
 )
 
 const (
@@ -33,10 +37,21 @@ const (
 	ROTATION_270 = 3
 )
 
-type arcSector struct {
-	ok             bool
-	xs, xe, ys, ye float64
-}
+const (
+	CLOCKWISE         ArcDirection = 0
+	COUNTER_CLOCKWISE ArcDirection = 1
+)
+
+const (
+	NEAREST_SAMPLING FontSampling = 0
+	AREA_SAMPLING    FontSampling = 1
+)
+
+var (
+	ErrFontNotImplemented = errors.New("font format is not implemented")
+	ErrFontNotDefined     = errors.New("font is not defined")
+	ErrCharOutOfRange     = errors.New("charCode code out of range")
+)
 
 type pixelDevice interface {
 	Pixel(x, y int, color any) error
@@ -46,8 +61,21 @@ type pixelDevice interface {
 	ScreenHeight() int
 }
 
+// errorReporter is implemented by devices whose Update can fail, e.g. when flushing to a panel.
+type errorReporter interface {
+	Err() error
+}
+
+// Sketcher draws on a pixel device. Coordinates are rounded to the nearest pixel and every
+// primitive covers both of its end coordinates: Line, Rectangle, FillRectangle and ClearArea
+// include both corners and the outline of a shape is always the edge of its fill, e.g.
+// FillCircle covers exactly the rows and the row extents of Circle with the same arguments.
+// Polygon fills, and the thick lines and curves built on them, cover the pixels whose centres
+// are inside the shape, including its left and right edges.
 type Sketcher interface {
 	Update() int
+	Err() error
+	DirtyRegions() []Rect
 	SetRotation(rotation float64)
 	ScreenWidth() float64
 	ScreenHeight() float64
@@ -56,18 +84,41 @@ type Sketcher interface {
 	Pixel(x, y float64, color any)
 	Line(x1, y1, x2, y2 float64, color any)
 	Arc(xc, yc, radius, startAngle, endAngle float64, color any)
+	DirectedArc(xc, yc, radius, startAngle, endAngle float64, direction ArcDirection, color any)
 	ThickArc(xc, yc, radius, startAngle, endAngle float64, width float64, widthType WidthType, color any)
 	Circle(x, y, radius float64, color any)
 	Rectangle(x1, y1, x2, y2 float64, color any)
 	FillCircle(x, y, radius float64, color any)
 	ThickCircle(x, y, radius float64, width float64, widthType WidthType, color any)
 	FillRectangle(x1, y1, x2, y2 float64, color any)
+	FillRectangleGradient(x1, y1, x2, y2 float64, from, to any, vertical bool)
 	ThickRectangle(x1, y1, x2, y2 float64, width float64, widthType WidthType, color any)
+	Polyline(points []Point, color any)
+	ThickPolyline(points []Point, width float64, color any)
+	FillPolygon(points []Point, color any)
+	QuadBezier(x1, y1, cx, cy, x2, y2 float64, color any)
+	CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color any)
+	Spline(points []Point, color any)
+	ThickQuadBezier(x1, y1, cx, cy, x2, y2 float64, width float64, color any)
+	ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, width float64, color any)
+	ThickSpline(points []Point, width float64, color any)
+	FillSpline(points []Point, color any)
+	StrokePath(path *Path, width float64, color any)
+	FillPath(path *Path, rule FillRule, color any)
 	SetFont(font any) error
 	WriteScaled(text string, xscale, yscale float64, color any)
 	Write(text string, color any)
+	WriteRotated(text string, angle float64, color any)
+	WriteRotatedScaled(text string, xscale, yscale, angle float64, color any)
 	MoveCursor(x, y float64)
 	GetTextArea(x, y float64, text string, xscale, yscale float64) (x1, y1, x2, y2 float64)
+	GetRotatedTextArea(x, y float64, text string, xscale, yscale, angle float64) (x1, y1, x2, y2 float64)
+	GetTextScaleToFit(text string, width, height float64) float64
+	SetFontSampling(sampling FontSampling)
+	SetFontSmoothing(enabled bool, background any)
+	SetLetterSpacing(spacing float64)
+	SetWordSpacing(spacing float64)
+	SetKerning(kerning KerningTable)
 }
 
 type sketcher struct {
@@ -77,18 +128,29 @@ type sketcher struct {
 	font            any
 	bitmapFont      fonts.BitmapFont
 	fontType        FontType
-	cursorX         int
-	cursorY         int
+	fontSampling    FontSampling
+	fontSmoothing   bool
+	cursorX         float64
+	cursorY         float64
 	charAdvanceX    int
 	textLeftPadding int
 	textTopPadding  int
 	rotation        int
+	letterSpacing   float64
+	wordSpacing     float64
+	kerning         KerningTable
+	err             error
+	dirty           []Rect
+	pending         Rect
+	hasPending      bool
 }
 
 func NewSketcher(pixeldev pixelDevice, defaultColor any) Sketcher {
 	s := sketcher{
 		pixeldev:        pixeldev,
 		fontType:        BITMAP_FONT,
+		fontSampling:    NEAREST_SAMPLING,
+		fontSmoothing:   false,
 		font:            fonts.FreeMono18pt7b,
 		cursorX:         0,
 		cursorY:         0,
@@ -96,14 +158,40 @@ func NewSketcher(pixeldev pixelDevice, defaultColor any) Sketcher {
 		textLeftPadding: 0,
 		textTopPadding:  0,
 		rotation:        ROTATION_0,
+		letterSpacing:   0,
+		wordSpacing:     0,
+		kerning:         nil,
 		color:           defaultColor,
 		bgColor:         defaultColor,
+		err:             nil,
+		dirty:           make([]Rect, 0),
+		hasPending:      false,
 	}
+	s.SetFont(fonts.FreeMono18pt7b)
 	return &s
 }
 
+// Update pushes the drawing to the device and starts a new error reporting period.
+// Devices that implement UpdateRegion only receive the dirty regions, the error of
+// devices that implement Err is reported by Err until the next Update.
 func (d *sketcher) Update() int {
-	return d.pixeldev.Update()
+	d.err = nil
+	n := d.updateDevice()
+	if dev, ok := d.pixeldev.(errorReporter); ok {
+		d.setErr(dev.Err())
+	}
+	return n
+}
+
+// Err returns the first error of the drawing calls since the last Update.
+func (d *sketcher) Err() error {
+	return d.err
+}
+
+func (d *sketcher) setErr(err error) {
+	if d.err == nil && err != nil {
+		d.err = err
+	}
 }
 
 func (d *sketcher) SetRotation(rotation float64) {
@@ -125,69 +213,81 @@ func (d *sketcher) ScreenHeight() float64 {
 }
 
 func (d *sketcher) ClearArea(x1, y1, x2, y2 float64, color any) {
-	xs := int(math.Round(x1))
-	xe := int(math.Round(x2))
-	ys := int(math.Round(y1))
-	ye := int(math.Round(y2))
-
-	if x1 > x2 {
-		t := xs
-		xs = xe
-		xe = t
+	defer d.commitDirty()
+	xs, xe := clipRange(math.Round(math.Min(x1, x2)), math.Round(math.Max(x1, x2)), d.ScreenWidth())
+	ys, ye := clipRange(math.Round(math.Min(y1, y2)), math.Round(math.Max(y1, y2)), d.ScreenHeight())
+	if xs > xe {
+		return
 	}
-	if y1 > y2 {
-		t := ys
-		ys = ye
-		ye = t
-	}
-
-	for x := xs; x <= xe; x += 1 {
-		for y := ys; y <= ye; y += 1 {
-			d.rotatedPixel(float64(x), float64(y), color)
-		}
+	for y := ys; y <= ye; y += 1 {
+		d.hspan(xs, xe, y, color)
 	}
 }
 
 // Drawing methods
 func (d *sketcher) Clear(color any) {
-	d.pixeldev.Clear(color)
+	if _, ok := color.(Paint); ok {
+		d.ClearArea(0, 0, d.ScreenWidth()-1, d.ScreenHeight()-1, color)
+		return
+	}
+	d.setErr(d.pixeldev.Clear(color))
+	d.markAllDirty()
 }
 
 func (d *sketcher) rotatePoint(x, y float64) (float64, float64) {
 	if d.rotation == ROTATION_0 {
 		return x, y
 	}
+	xmax := float64(d.pixeldev.ScreenWidth() - 1)
+	ymax := float64(d.pixeldev.ScreenHeight() - 1)
 	if d.rotation == ROTATION_90 {
-		return float64(d.pixeldev.ScreenWidth()) - y, x
+		return xmax - y, x
 	}
 	if d.rotation == ROTATION_180 {
-		return float64(d.pixeldev.ScreenWidth()) - x, float64(d.pixeldev.ScreenHeight()) - y
+		return xmax - x, ymax - y
 	}
-	return y, float64(d.pixeldev.ScreenHeight()) - x
+	return y, ymax - x
 }
 
+// rotatedPixel rounds the point to a screen pixel before rotating it, so all
+// primitives land on the same pixels in every rotation.
 func (d *sketcher) rotatedPixel(x, y float64, color any) {
+	x = math.Round(x)
+	y = math.Round(y)
 	rotatedX, rotatedY := d.rotatePoint(x, y)
-	d.pixeldev.Pixel(int(math.Round(rotatedX)), int(math.Round(rotatedY)), color)
+	d.devicePixel(int(rotatedX), int(rotatedY), paintAt(color, x, y))
+}
+
+func (d *sketcher) devicePixel(x, y int, color any) {
+	d.setErr(d.pixeldev.Pixel(x, y, color))
+	d.markDirty(x, y)
 }
 
 func (d *sketcher) Pixel(x, y float64, color any) {
+	defer d.commitDirty()
 	d.rotatedPixel(x, y, color)
 }
 
 func (d *sketcher) Line(x1, y1, x2, y2 float64, color any) {
+	defer d.commitDirty()
 	// Bresenham's line algorithm https://en.wikipedia.org/wiki/Bresenham%27s_line_algorithm
 	xs := int(math.Round(x1))
 	ys := int(math.Round(y1))
 	xe := int(math.Round(x2))
 	ye := int(math.Round(y2))
-	dx := int(math.Abs(x2 - x1))
+	dx := xe - xs
+	if dx < 0 {
+		dx = -dx
+	}
 	// sx := xs < xe ? 1 : -1
 	sx := -1
 	if xs < xe {
 		sx = 1
 	}
-	dy := -int(math.Abs(y2 - y1))
+	dy := ys - ye
+	if dy > 0 {
+		dy = -dy
+	}
 	// sy := ys < ye ? 1 : -1
 	sy := -1
 	if ys < ye {
@@ -218,143 +318,175 @@ func (d *sketcher) Line(x1, y1, x2, y2 float64, color any) {
 	}
 }
 
-func isInsideSector0(x, y, xs, ys, xe, ye float64) bool {
-	return x <= xs && x > xe && y >= ys && y < ye
-}
-
-func isInsideSector1(x, y, xs, ys, xe, ye float64) bool {
-	return x <= xs && x > xe && y <= ys && y > ye
-}
-
-func isInsideSector2(x, y, xs, ys, xe, ye float64) bool {
-	return x >= xs && x < xe && y <= ys && y > ye
-}
-
-func isInsideSector3(x, y, xs, ys, xe, ye float64) bool {
-	return x >= xs && x < xe && y >= ys && y < ye
+func DegToRad(degree float64) float64 {
+	return degree * math.Pi / 180
 }
 
-func findArcSectors(startAngle, endAngle, radius float64) map[int][]arcSector {
-	var sectorsmap map[int][]arcSector = map[int][]arcSector{
-		0: make([]arcSector, 0),
-		1: make([]arcSector, 0),
-		2: make([]arcSector, 0),
-		3: make([]arcSector, 0),
-	}
-	PI2 := math.Pi / 2
-	from := math.Mod(startAngle, math.Pi*2)
-	to := math.Mod(endAngle, math.Pi*2)
-	if to < from {
-		to += math.Pi * 2
-	}
-	angle := from
-	for sec := 0; angle < to; sec++ {
-		sector := arcSector{
-			ok: false,
-			xs: 0,
-			ys: 0,
-			xe: 0,
-			ye: 0,
-		}
-		s := float64(sec) * PI2
-		e := float64(sec+1) * PI2
-		if e >= to {
-			e = to
-		}
-		if angle >= s && angle < e {
-			sector.ok = true
-			sector.xs = radius * math.Cos(angle)
-			sector.ys = radius * math.Sin(angle)
-			sector.xe = radius * math.Cos(e)
-			sector.ye = radius * math.Sin(e)
-			angle = e
-			sectorsmap[sec%4] = append(sectorsmap[sec%4], sector)
-		}
-	}
-	// showSectors(sectorsmap)
-	return sectorsmap
+func RadToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
 }
 
-func (dev *sketcher) arcPutPixel(sector int, xc, yc, x, y float64, s arcSector, color any) {
-	tests := []func(x, y, xs, ys, xe, ye float64) bool{
-		isInsideSector0,
-		isInsideSector1,
-		isInsideSector2,
-		isInsideSector3,
-	}
-	if tests[sector](x, y, s.xs, s.ys, s.xe, s.ye) {
-		dev.rotatedPixel(x+xc, y+yc, color)
-	}
+// IsFullCircle tells if the arc from startAngle to endAngle goes all the way around.
+func IsFullCircle(startAngle, endAngle float64) bool {
+	return math.Abs(endAngle-startAngle) >= DEG360-1e-9
 }
 
+// Arc draws the outline of Circle from startAngle to endAngle in increasing angles, which is
+// clockwise on the screen. Angles are in radians, may be negative and an arc of 2π or more is a full circle.
 func (dev *sketcher) Arc(xc, yc, radius, startAngle, endAngle float64, color any) {
-	signs := [4][2]float64{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
-	iradius := math.Round(radius)
-	sectormaps := findArcSectors(startAngle, endAngle, iradius)
-	var iradius2 = iradius * iradius
-	var l1 float64 = 0
-	for l1 = 0; true; l1 += 1 {
-		l2 := math.Sqrt(iradius2 - l1*l1)
-		for sector := 0; sector < 4; sector++ {
-			sectors := sectormaps[sector]
-			for i := 0; i < len(sectors); i++ {
-				if sectors[i].ok {
-					dev.arcPutPixel(sector, xc, yc, signs[sector][0]*l1, signs[sector][1]*l2, sectors[i], color)
-					dev.arcPutPixel(sector, xc, yc, signs[sector][0]*l2, signs[sector][1]*l1, sectors[i], color)
-				}
-			}
+	defer dev.commitDirty()
+	full := IsFullCircle(startAngle, endAngle)
+	circlePoints(xc, yc, radius, func(px, py int) {
+		if full || isAngleInArc(math.Atan2(float64(py)-yc, float64(px)-xc), startAngle, endAngle) {
+			dev.rotatedPixel(float64(px), float64(py), color)
 		}
+	})
+}
 
-		if l1 >= l2 {
-			break
-		}
+// DirectedArc draws the arc from startAngle to endAngle going around in the given direction.
+func (dev *sketcher) DirectedArc(xc, yc, radius, startAngle, endAngle float64, direction ArcDirection, color any) {
+	if direction == COUNTER_CLOCKWISE {
+		startAngle, endAngle = endAngle, startAngle
 	}
+	dev.Arc(xc, yc, radius, startAngle, endAngle, color)
 }
 
+// ThickArc fills the part of the ring between startAngle and endAngle, see ThickCircle.
 func (dev *sketcher) ThickArc(xc, yc, radius, startAngle, endAngle float64, width float64, widthType WidthType, color any) {
-	rs := calcThicknessStart(radius, width, widthType)
-	for dr := 0; dr < int(width); dr++ {
-		dev.Arc(xc, yc, rs-float64(dr), startAngle, endAngle, color)
+	defer dev.commitDirty()
+	ro := ThicknessStart(radius, width, widthType)
+	if IsFullCircle(startAngle, endAngle) {
+		dev.fillAnnulus(xc, yc, ro-width, ro, nil, color)
+		return
 	}
+	dev.fillAnnulus(xc, yc, ro-width, ro, func(dx, dy float64) bool {
+		return isAngleInArc(math.Atan2(dy, dx), startAngle, endAngle)
+	}, color)
 }
 
 func (dev *sketcher) Circle(x, y, radius float64, color any) {
+	defer dev.commitDirty()
+	circlePoints(x, y, radius, func(px, py int) {
+		dev.rotatedPixel(float64(px), float64(py), color)
+	})
+}
+
+// circlePoints calls putpixel for the screen pixels of the circle outline.
+func circlePoints(xc, yc, radius float64, putpixel func(x, y int)) {
 	// Midpoint circle algorithm https://en.wikipedia.org/wiki/Midpoint_circle_algorithm
-	putpixels := func(xc, yc, dr, d float64) {
-		dev.rotatedPixel(xc+d, yc+dr, color)
-		dev.rotatedPixel(xc+d, yc-dr, color)
-		dev.rotatedPixel(xc+dr, yc+d, color)
-		dev.rotatedPixel(xc+dr, yc-d, color)
+	putpixels := func(dr, d float64) {
+		putpixel(int(math.Round(xc+d)), int(math.Round(yc+dr)))
+		putpixel(int(math.Round(xc+d)), int(math.Round(yc-dr)))
+		putpixel(int(math.Round(xc+dr)), int(math.Round(yc+d)))
+		putpixel(int(math.Round(xc+dr)), int(math.Round(yc-d)))
 
-		dev.rotatedPixel(xc-d, yc+dr, color)
-		dev.rotatedPixel(xc-d, yc-dr, color)
-		dev.rotatedPixel(xc-dr, yc+d, color)
-		dev.rotatedPixel(xc-dr, yc-d, color)
+		putpixel(int(math.Round(xc-d)), int(math.Round(yc+dr)))
+		putpixel(int(math.Round(xc-d)), int(math.Round(yc-dr)))
+		putpixel(int(math.Round(xc-dr)), int(math.Round(yc+d)))
+		putpixel(int(math.Round(xc-dr)), int(math.Round(yc-d)))
 	}
 
 	var dy float64 = radius
 	for dx := float64(0); dx < dy; dx += 1 {
 		dy = math.Sqrt(radius*radius - dx*dx)
-		putpixels(x, y, dx, dy)
+		putpixels(dx, dy)
 	}
 }
 
+// FillCircle fills every row of the Circle outline from its leftmost to its rightmost pixel.
+// The rows are computed one by one for the rows on the screen, so the time does not grow
+// with the radius. Infinite and NaN radii are not drawn.
 func (dev *sketcher) FillCircle(x, y, radius float64, color any) {
-	// Midpoint circle algorithm https://en.wikipedia.org/wiki/Midpoint_circle_algorithm
-	putpixels := func(xc, yc, dr, d float64) {
-		dev.Line(xc+d, yc+dr, xc-d, yc+dr, color)
-		dev.Line(xc+d, yc-dr, xc-d, yc-dr, color)
+	defer dev.commitDirty()
+	if math.IsNaN(radius) || math.IsInf(radius*radius, 0) {
+		return
+	}
+	steps := circleSteps(radius)
+	ys, ye := clipRange(math.Round(y-radius)-1, math.Round(y+radius)+1, dev.ScreenHeight())
+	for py := ys; py <= ye; py++ {
+		left, right, ok := circleRow(x, y, radius, steps, float64(py))
+		if !ok {
+			continue
+		}
+		if x1, x2 := clipRange(left, right, dev.ScreenWidth()); x1 <= x2 {
+			dev.hspan(x1, x2, py, color)
+		}
+	}
+}
 
-		dev.Line(xc+dr, yc+d, xc-dr, yc+d, color)
-		dev.Line(xc+dr, yc-d, xc-dr, yc-d, color)
+// circleSteps returns the number of steps of the loop of circlePoints.
+func circleSteps(radius float64) float64 {
+	inLoop := func(dx float64) bool {
+		if dx == 0 {
+			return 0 < radius
+		}
+		return dx < math.Sqrt(radius*radius-(dx-1)*(dx-1))
+	}
+	estimate := (1 + math.Sqrt(math.Max(0, 2*radius*radius-1))) / 2
+	return lastStep(math.Floor(estimate)+4, estimate, inLoop) + 1
+}
+
+// lastStep returns the last of the steps 0..last for which holds, which holds for the steps
+// up to some step and not after it, or -1 if there is none. It starts from an estimate of the
+// step and takes a few steps from there, so a rough estimate gives a near but wrong step.
+func lastStep(last, estimate float64, holds func(step float64) bool) float64 {
+	step := -1.0
+	if !math.IsNaN(estimate) {
+		step = math.Max(-1, math.Min(last, math.Floor(estimate)))
+	}
+	for i := 0; i < 4 && step < last && holds(step+1); i++ {
+		step++
+	}
+	for i := 0; i < 4 && step >= 0 && !holds(step); i++ {
+		step--
+	}
+	return step
+}
+
+// circleRow returns the leftmost and rightmost pixel circlePoints draws on the row y, steps
+// is circleSteps of the radius.
+func circleRow(xc, yc, radius, steps, y float64) (float64, float64, bool) {
+	d := func(dx float64) float64 {
+		return math.Sqrt(radius*radius - dx*dx)
+	}
+	// the widest of the rows yc±dx and of the columns xc±dx of the steps dx on the row
+	offset := -1.0
+	for dx := math.Floor(math.Abs(y-yc)) - 1; dx <= math.Floor(math.Abs(y-yc))+1; dx++ {
+		if dx < 0 || dx >= steps {
+			continue
+		}
+		if math.Round(yc+dx) == y || math.Round(yc-dx) == y {
+			offset = math.Max(offset, d(dx))
+		}
+	}
+	// the steps dx whose rows are at or after y, and before or at y
+	estimate := func(t float64) float64 {
+		if t <= 0 {
+			return steps - 1
+		}
+		return math.Sqrt(radius*radius - t*t)
+	}
+	below := lastStep(steps-1, estimate(y-yc-0.5), func(dx float64) bool {
+		return math.Round(yc+d(dx)) >= y
+	})
+	if below >= 0 && math.Round(yc+d(below)) == y {
+		offset = math.Max(offset, below)
+	}
+	above := lastStep(steps-1, estimate(yc-y-0.5), func(dx float64) bool {
+		return math.Round(yc-d(dx)) <= y
+	})
+	if above >= 0 && math.Round(yc-d(above)) == y {
+		offset = math.Max(offset, above)
 	}
-	for dr := float64(0); dr <= math.Ceil(radius*0.707); dr += 1 {
-		d := math.Sqrt(radius*radius - dr*dr)
-		putpixels(x, y, dr, d)
+	if offset < 0 {
+		return 0, 0, false
 	}
+	return math.Round(xc - offset), math.Round(xc + offset), true
 }
 
-func calcThicknessStart(mid float64, width float64, widthType WidthType) float64 {
+// ThicknessStart returns the outer edge of a line of the width drawn at mid, e.g. the outer
+// radius of a thick circle.
+func ThicknessStart(mid float64, width float64, widthType WidthType) float64 {
 	from := mid
 	switch widthType {
 	case OUTER_WIDTH:
@@ -365,14 +497,16 @@ func calcThicknessStart(mid float64, width float64, widthType WidthType) float64
 	return from
 }
 
+// ThickCircle fills the ring of the given width around the circle. The pixels whose centres
+// are inside the ring are drawn, so the ring is solid for any, also fractional, width.
 func (dev *sketcher) ThickCircle(x, y, radius float64, width float64, widthType WidthType, color any) {
-	rs := calcThicknessStart(radius, width, widthType)
-	for dr := 0; dr < int(width); dr++ {
-		dev.Circle(x, y, rs-float64(dr), color)
-	}
+	defer dev.commitDirty()
+	ro := ThicknessStart(radius, width, widthType)
+	dev.fillAnnulus(x, y, ro-width, ro, nil, color)
 }
 
 func (dev *sketcher) Rectangle(x1, y1, x2, y2 float64, color any) {
+	defer dev.commitDirty()
 	dev.Line(x1, y1, x2, y1, color)
 	dev.Line(x2, y1, x2, y2, color)
 	dev.Line(x2, y2, x1, y2, color)
@@ -380,18 +514,19 @@ func (dev *sketcher) Rectangle(x1, y1, x2, y2 float64, color any) {
 }
 
 func (dev *sketcher) FillRectangle(x1, y1, x2, y2 float64, color any) {
-	l := math.Round(y2 - y1)
-	dy := float64(1)
-	if l < 0 {
-		dy = -1
+	defer dev.commitDirty()
+	xs, xe := clipRange(math.Round(math.Min(x1, x2)), math.Round(math.Max(x1, x2)), dev.ScreenWidth())
+	ys, ye := clipRange(math.Round(math.Min(y1, y2)), math.Round(math.Max(y1, y2)), dev.ScreenHeight())
+	if xs > xe {
+		return
 	}
-
-	for y := float64(0); y != l; y += dy {
-		dev.Line(x1, y1+y, x2, y1+y, color)
+	for y := ys; y <= ye; y++ {
+		dev.hspan(xs, xe, y, color)
 	}
 }
 
 func (dev *sketcher) ThickRectangle(x1, y1, x2, y2 float64, width float64, widthType WidthType, color any) {
+	defer dev.commitDirty()
 	xs := x1
 	xe := x2
 	if x2 < x1 {
@@ -404,7 +539,7 @@ func (dev *sketcher) ThickRectangle(x1, y1, x2, y2 float64, width float64, width
 		ys = y2
 		ye = y1
 	}
-	s := calcThicknessStart(0, width, widthType)
+	s := ThicknessStart(0, width, widthType)
 	for dxy := float64(0); dxy < float64(width); dxy++ {
 		dev.Rectangle(xs-s+dxy, ys-s+dxy, xe+s-dxy, ye+s-dxy, color)
 	}
@@ -417,50 +552,69 @@ func (dev *sketcher) SetFont(font any) error {
 		dev.bitmapFont = bitmapfont
 		return nil
 	}
-	return errors.New("font format is not implemented")
+	return ErrFontNotImplemented
 }
 
-func (dev *sketcher) writeChar(char byte, xscale, yscale float64, color any) error {
+// checkChar tells if the font has the character.
+func (dev *sketcher) checkChar(char byte) error {
 	if char < ' ' || char > '~' {
-		return errors.New("charCode code out of range")
+		return ErrCharOutOfRange
 	}
-
-	switch dev.fontType {
-	case BITMAP_FONT:
-		dev.drawBitmapChar(char, xscale, yscale, color)
-	default:
-		return errors.New("font is not defined")
+	if dev.fontType != BITMAP_FONT || int(char-0x20) >= len(dev.bitmapFont.Glyphs) {
+		return ErrFontNotDefined
 	}
 	return nil
 }
 
-func (dev *sketcher) WriteScaled(text string, xscale, yscale float64, color any) {
-	if xscale < 1 {
-		xscale = 1
+// ClampFontScale limits a text scale to MIN_FONT_SCALE..MAX_FONT_SCALE.
+func ClampFontScale(scale float64) float64 {
+	if scale < MIN_FONT_SCALE {
+		return MIN_FONT_SCALE
 	}
-	if yscale < 1 {
-		yscale = 1
-	}
-	if xscale > float64(MAX_FONT_SCALE) {
-		xscale = float64(MAX_FONT_SCALE)
-	}
-	if yscale > float64(MAX_FONT_SCALE) {
-		yscale = float64(MAX_FONT_SCALE)
-	}
-	for i := 0; i < len(text); i++ {
-		dev.writeChar(text[i], xscale, yscale, color)
+	if scale > float64(MAX_FONT_SCALE) {
+		return float64(MAX_FONT_SCALE)
 	}
+	return scale
+}
+
+func (dev *sketcher) WriteScaled(text string, xscale, yscale float64, color any) {
+	dev.writeText(text, ClampFontScale(xscale), ClampFontScale(yscale), 0, color)
 }
 
 func (dev *sketcher) Write(text string, color any) {
-	for i := 0; i < len(text); i++ {
-		dev.writeChar(text[i], 1, 1, color)
+	dev.writeText(text, 1, 1, 0, color)
+}
+
+// WriteRotated writes the text along a baseline turned by angle (radians, clockwise on screen)
+// around the cursor, independent of the screen rotation.
+func (dev *sketcher) WriteRotated(text string, angle float64, color any) {
+	dev.writeText(text, 1, 1, angle, color)
+}
+
+func (dev *sketcher) WriteRotatedScaled(text string, xscale, yscale, angle float64, color any) {
+	dev.writeText(text, ClampFontScale(xscale), ClampFontScale(yscale), angle, color)
+}
+
+func (dev *sketcher) writeText(text string, xscale, yscale, angle float64, color any) {
+	defer dev.commitDirty()
+	dev.setErr(dev.eachChar(text, func(char byte, kerning float64) {
+		dev.advanceCursor(kerning*xscale, angle)
+		dev.drawBitmapChar(char, xscale, yscale, angle, color)
+	}))
+}
+
+func (dev *sketcher) advanceCursor(distance, angle float64) {
+	if angle == 0 {
+		dev.cursorX += distance
+		return
 	}
+	dev.cursorX += distance * math.Cos(angle)
+	dev.cursorY += distance * math.Sin(angle)
 }
 
 func (dev *sketcher) MoveCursor(x, y float64) {
-	dev.cursorX = int(x)
-	dev.cursorY = int(y)
+	dev.cursorX = x
+	dev.cursorY = y
 }
 
 func (dev *sketcher) GetTextArea(x, y float64, text string, xscale, yscale float64) (x1, y1, x2, y2 float64) {
@@ -475,43 +629,196 @@ func (dev *sketcher) GetTextArea(x, y float64, text string, xscale, yscale float
 	return
 }
 
-func (dev *sketcher) drawBitmapChar(char byte, xscale, yscale float64, color any) {
+// GetRotatedTextArea returns the bounding box of the text written by WriteRotatedScaled from (x, y).
+func (dev *sketcher) GetRotatedTextArea(x, y float64, text string, xscale, yscale, angle float64) (x1, y1, x2, y2 float64) {
+	ax1, ay1, ax2, ay2 := dev.GetTextArea(x, y, text, xscale, yscale)
+	if angle == 0 {
+		return ax1, ay1, ax2, ay2
+	}
+	corners := [4][2]float64{{ax1, ay1}, {ax2, ay1}, {ax2, ay2}, {ax1, ay2}}
+	for i := 0; i < len(corners); i++ {
+		cx, cy := rotateAround(corners[i][0], corners[i][1], x, y, angle)
+		if i == 0 || cx < x1 {
+			x1 = cx
+		}
+		if i == 0 || cy < y1 {
+			y1 = cy
+		}
+		if i == 0 || cx > x2 {
+			x2 = cx
+		}
+		if i == 0 || cy > y2 {
+			y2 = cy
+		}
+	}
+	return
+}
+
+func rotateAround(x, y, xc, yc, angle float64) (float64, float64) {
+	sin, cos := math.Sincos(angle)
+	dx := x - xc
+	dy := y - yc
+	return xc + dx*cos - dy*sin, yc + dx*sin + dy*cos
+}
+
+// GlyphPixel tells if the pixel w, h of the glyph of the font is set, pixels outside of the
+// glyph are not.
+func GlyphPixel(font fonts.BitmapFont, glyph fonts.Glyph, w, h int) bool {
+	if w < 0 || w >= glyph.Width || h < 0 || h >= glyph.Height {
+		return false
+	}
+	bitIndex := h*glyph.Width + w
+	shift := byte(bitIndex) % 8
+	d := font.Bitmap[glyph.BitmapOffset+bitIndex/8]
+	mask := byte(0b10000000) >> shift
+	return d&mask != 0
+}
+
+func (dev *sketcher) drawBitmapChar(char byte, xscale, yscale, angle float64, color any) {
 	glyph := dev.bitmapFont.Glyphs[char-0x20]
-	for h := 0; h < glyph.Height; h++ {
-		for w := 0; w < glyph.Width; w++ {
-			bitIndex := h*glyph.Width + w
-			shift := byte(bitIndex) % 8
-			d := dev.bitmapFont.Bitmap[glyph.BitmapOffset+bitIndex/8]
-			mask := byte(0b10000000) >> shift
-			bit := d & mask
-			pixel := false
-			if bit != 0 {
-				pixel = true
+	// glyph box relative to the cursor before rotation
+	gx1 := float64(glyph.XOffset) * xscale
+	gy1 := float64(glyph.YOffset) * yscale
+	gx2 := gx1 + float64(glyph.Width)*xscale
+	gy2 := gy1 + float64(glyph.Height)*yscale
+
+	x1, y1, x2, y2 := gx1, gy1, gx2, gy2
+	if angle != 0 {
+		corners := [4][2]float64{{gx1, gy1}, {gx2, gy1}, {gx2, gy2}, {gx1, gy2}}
+		for i := 0; i < len(corners); i++ {
+			cx, cy := rotateAround(corners[i][0], corners[i][1], 0, 0, angle)
+			if i == 0 || cx < x1 {
+				x1 = cx
 			}
-			x := dev.cursorX + (w+glyph.XOffset)*int(xscale)
-			y := dev.cursorY + (h+glyph.YOffset)*int(yscale)
-
-			for dx := 0; dx < int(xscale); dx++ {
-				for dy := 0; dy < int(yscale); dy++ {
-					if pixel {
-						dev.rotatedPixel(float64(x+dx), float64(y+dy), color)
-					}
+			if i == 0 || cy < y1 {
+				y1 = cy
+			}
+			if i == 0 || cx > x2 {
+				x2 = cx
+			}
+			if i == 0 || cy > y2 {
+				y2 = cy
+			}
+		}
+	}
 
+	// every screen pixel around the glyph is mapped back into the glyph bitmap
+	sin, cos := math.Sincos(-angle)
+	sample := func(dx, dy float64) bool {
+		lx := dx
+		ly := dy
+		if angle != 0 {
+			lx = dx*cos - dy*sin
+			ly = dx*sin + dy*cos
+		}
+		w := int(math.Floor(lx/xscale)) - glyph.XOffset
+		h := int(math.Floor(ly/yscale)) - glyph.YOffset
+		return GlyphPixel(dev.bitmapFont, glyph, w, h)
+	}
+	for y := math.Floor(dev.cursorY + y1); y < dev.cursorY+y2; y++ {
+		for x := math.Floor(dev.cursorX + x1); x < dev.cursorX+x2; x++ {
+			dx := x - dev.cursorX
+			dy := y - dev.cursorY
+			if dev.fontSampling == NEAREST_SAMPLING && !dev.fontSmoothing {
+				if sample(dx+0.5, dy+0.5) {
+					dev.rotatedPixel(x, y, color)
+				}
+				continue
+			}
+			coverage := glyphCoverage(dx, dy, sample)
+			if dev.fontSmoothing {
+				if coverage > 0 {
+					dev.rotatedPixel(x, y, blendColors(dev.bgColor, paintAt(color, x, y), coverage))
 				}
+			} else if coverage >= 0.5 {
+				dev.rotatedPixel(x, y, color)
+			}
+		}
+	}
+	dev.advanceCursor(dev.charAdvance(char)*xscale, angle)
+}
+
+// glyphCoverage estimates the part of the pixel at (dx, dy) covered by the glyph using a 4x4 grid of samples.
+func glyphCoverage(dx, dy float64, sample func(dx, dy float64) bool) float64 {
+	const N = 4
+	covered := 0
+	for sy := 0; sy < N; sy++ {
+		for sx := 0; sx < N; sx++ {
+			if sample(dx+(float64(sx)+0.5)/N, dy+(float64(sy)+0.5)/N) {
+				covered++
 			}
 		}
 	}
-	dev.cursorX += glyph.XAdvance * int(xscale)
+	return float64(covered) / (N * N)
+}
+
+// blendColors mixes a Color foreground into any background ToColor converts, and two
+// 0xRRGGBB colours of the same uint32 based type. Any other colour type cannot be mixed
+// and the foreground is used for coverage of at least one half.
+func blendColors(background, foreground any, coverage float64) any {
+	if coverage >= 1 {
+		return foreground
+	}
+	if fg, ok := foreground.(Color); ok {
+		if bg, err := ToColor(background); err == nil {
+			return bg.Mix(fg, coverage)
+		}
+	}
+	bg := reflect.ValueOf(background)
+	fg := reflect.ValueOf(foreground)
+	if !bg.IsValid() || !fg.IsValid() || bg.Type() != fg.Type() || fg.Kind() != reflect.Uint32 {
+		if coverage >= 0.5 {
+			return foreground
+		}
+		return background
+	}
+	b := bg.Uint()
+	f := fg.Uint()
+	var mixed uint64 = 0
+	for shift := 0; shift < 24; shift += 8 {
+		cb := float64((b >> shift) & 0xFF)
+		cf := float64((f >> shift) & 0xFF)
+		mixed |= uint64(math.Round(cb+(cf-cb)*coverage)) << shift
+	}
+	result := reflect.New(fg.Type()).Elem()
+	result.SetUint(mixed)
+	return result.Interface()
+}
+
+func (dev *sketcher) SetFontSampling(sampling FontSampling) {
+	dev.fontSampling = sampling
+}
+
+// SetFontSmoothing blends the edges of scaled glyphs into the given background colour.
+func (dev *sketcher) SetFontSmoothing(enabled bool, background any) {
+	dev.fontSmoothing = enabled
+	if enabled {
+		dev.bgColor = background
+	}
+}
+
+// GetTextScaleToFit returns the largest uniform scale that keeps the text inside width x height.
+func (dev *sketcher) GetTextScaleToFit(text string, width, height float64) float64 {
+	x1, y1, x2, y2 := dev.GetTextArea(0, 0, text, 1, 1)
+	scale := float64(MAX_FONT_SCALE)
+	if x2 > x1 && width/(x2-x1) < scale {
+		scale = width / (x2 - x1)
+	}
+	if y2 > y1 && height/(y2-y1) < scale {
+		scale = height / (y2 - y1)
+	}
+	return ClampFontScale(scale)
 }
 
 func (dev *sketcher) getBitmapFontTextArea(x, y float64, text string, xscale, yscale float64) (float64, float64, float64, float64) {
-	bytes := []byte(text)
 	ymax := 0
 	ymin := 0
-	xmax := 0
-	for i := 0; i < len(bytes); i++ {
-		glyph := dev.bitmapFont.Glyphs[bytes[i]-0x20]
-		xmax += glyph.XAdvance
+	xmax := float64(0)
+	chars := 0
+	dev.eachChar(text, func(char byte, kerning float64) {
+		glyph := dev.bitmapFont.Glyphs[char-0x20]
+		xmax += kerning + dev.charAdvance(char)
+		chars++
 		yg := glyph.YOffset + glyph.Height
 		if yg > ymax {
 			ymax = yg
@@ -519,7 +826,11 @@ func (dev *sketcher) getBitmapFontTextArea(x, y float64, text string, xscale, ys
 		if glyph.YOffset < ymin {
 			ymin = glyph.YOffset
 		}
+	})
+	if chars > 0 {
+		// the spacing after the last character is not part of the text
+		xmax -= dev.letterSpacing
 	}
 	height := ymax - ymin
-	return x, y + float64(ymin)*yscale, x + float64(xmax)*xscale, y + float64(ymin+height)*yscale
+	return x, y + float64(ymin)*yscale, x + xmax*xscale, y + float64(ymin+height)*yscale
 }
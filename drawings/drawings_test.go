@@ -0,0 +1,151 @@
+package drawings
+
+import (
+	"fmt"
+	"testing"
+)
+
+// countingDevice counts the Pixel calls of every screen pixel.
+type countingDevice struct {
+	width  int
+	height int
+	counts []int
+}
+
+func newCountingDevice(width, height int) *countingDevice {
+	return &countingDevice{
+		width:  width,
+		height: height,
+		counts: make([]int, width*height),
+	}
+}
+
+func (dev *countingDevice) Pixel(x, y int, color any) error {
+	if x >= 0 && y >= 0 && x < dev.width && y < dev.height {
+		dev.counts[y*dev.width+x]++
+	}
+	return nil
+}
+
+func (dev *countingDevice) Clear(color any) error {
+	return nil
+}
+
+func (dev *countingDevice) Update() int {
+	return 0
+}
+
+func (dev *countingDevice) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *countingDevice) ScreenHeight() int {
+	return dev.height
+}
+
+// reset forgets the counted pixels.
+func (dev *countingDevice) reset() {
+	for i := range dev.counts {
+		dev.counts[i] = 0
+	}
+}
+
+// drawn returns the counts of the pixels drawn by draw.
+func (dev *countingDevice) drawn(draw func()) []int {
+	dev.reset()
+	draw()
+	counts := make([]int, len(dev.counts))
+	copy(counts, dev.counts)
+	return counts
+}
+
+// checkOutlineInFill checks that no fill pixel is drawn twice and that the fill covers every
+// row of the sketcher exactly from the leftmost to the rightmost outline pixel of the row. The
+// rows are device columns when rotated by 90 or 270 degrees. A fill may reach beyond the
+// outline to the edge of the screen where the outline is off the screen.
+func checkOutlineInFill(t *testing.T, name string, outline, fill []int, width int, rotated bool) {
+	t.Helper()
+	height := len(fill) / width
+	lines, length := height, width
+	index := func(line, i int) int { return line*width + i }
+	if rotated {
+		lines, length = width, height
+		index = func(line, i int) int { return i*width + line }
+	}
+	for line := 0; line < lines; line++ {
+		o0, o1, f0, f1 := -1, -1, -1, -1
+		for i := 0; i < length; i++ {
+			n := fill[index(line, i)]
+			if n > 1 {
+				t.Errorf("%s: fill draws line %d pixel %d %d times", name, line, i, n)
+				return
+			}
+			if n == 1 {
+				if f1 >= 0 && f1 != i-1 {
+					t.Errorf("%s: the fill of line %d has a gap before pixel %d", name, line, i)
+					return
+				}
+				if f0 < 0 {
+					f0 = i
+				}
+				f1 = i
+			}
+			if outline[index(line, i)] > 0 {
+				if o0 < 0 {
+					o0 = i
+				}
+				o1 = i
+			}
+		}
+		switch {
+		case o0 < 0 && f0 >= 0 && (f0 != 0 || f1 != length-1):
+			t.Errorf("%s: line %d has no outline but is filled from %d to %d", name, line, f0, f1)
+			return
+		case o0 < 0:
+		case f0 < 0 || o0 < f0 || o1 > f1:
+			t.Errorf("%s: line %d is filled from %d to %d, outside of the outline %d to %d", name, line, f0, f1, o0, o1)
+			return
+		case (f0 != o0 && f0 != 0) || (f1 != o1 && f1 != length-1):
+			t.Errorf("%s: line %d is filled from %d to %d, not the outline %d to %d", name, line, f0, f1, o0, o1)
+			return
+		}
+	}
+}
+
+func TestCircleOutlineInFill(t *testing.T) {
+	dev := newCountingDevice(40, 30)
+	s := NewSketcher(dev, 0)
+	for rotation := ROTATION_0; rotation <= ROTATION_270; rotation++ {
+		s.SetRotation(float64(rotation))
+		for radius := 0.0; radius <= 10.3; radius += 0.1 {
+			for _, c := range []Point{{14, 14}, {14.5, 14.5}, {14.3, 13.7}, {14.49, 14.51}} {
+				outline := dev.drawn(func() { s.Circle(c.X, c.Y, radius, 1) })
+				fill := dev.drawn(func() { s.FillCircle(c.X, c.Y, radius, 1) })
+				name := fmt.Sprintf("rotation %d, circle at %v radius %.1f", rotation, c, radius)
+				checkOutlineInFill(t, name, outline, fill, dev.width, rotation%2 == 1)
+			}
+		}
+	}
+}
+
+func TestRectangleOutlineInFill(t *testing.T) {
+	dev := newCountingDevice(40, 30)
+	s := NewSketcher(dev, 0)
+	corners := []float64{-2.5, 0, 0.4, 0.5, 3.49, 3.5, 7, 12.7, 28.5, 41}
+	for rotation := ROTATION_0; rotation <= ROTATION_270; rotation++ {
+		s.SetRotation(float64(rotation))
+		for _, x1 := range corners {
+			for _, y1 := range corners {
+				x2, y2 := 40-y1/2, 30-x1/3
+				for _, r := range [][4]float64{{x1, y1, x2, y2}, {x2, y2, x1, y1}, {x1, y2, x2, y1}} {
+					outline := dev.drawn(func() { s.Rectangle(r[0], r[1], r[2], r[3], 1) })
+					fill := dev.drawn(func() { s.FillRectangle(r[0], r[1], r[2], r[3], 1) })
+					area := dev.drawn(func() { s.ClearArea(r[0], r[1], r[2], r[3], 1) })
+					name := fmt.Sprintf("rotation %d, rectangle %v", rotation, r)
+					checkOutlineInFill(t, name, outline, fill, dev.width, rotation%2 == 1)
+					checkOutlineInFill(t, name+" clear area", outline, area, dev.width, rotation%2 == 1)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package drawings
+
+type KerningPair struct {
+	Left  byte
+	Right byte
+}
+
+// KerningTable holds the horizontal adjustment, in font pixels, applied between two adjacent characters.
+type KerningTable map[KerningPair]float64
+
+func (dev *sketcher) SetLetterSpacing(spacing float64) {
+	dev.letterSpacing = spacing
+}
+
+func (dev *sketcher) SetWordSpacing(spacing float64) {
+	dev.wordSpacing = spacing
+}
+
+func (dev *sketcher) SetKerning(kerning KerningTable) {
+	dev.kerning = kerning
+}
+
+// eachChar calls each for the characters of the text the font has, with the kerning after
+// the character before. Writing and measuring text share it so they advance alike: other
+// characters are skipped without separating a kerning pair, and the error of the first of
+// them is returned.
+func (dev *sketcher) eachChar(text string, each func(char byte, kerning float64)) error {
+	var err error
+	var prev byte = 0
+	for i := 0; i < len(text); i++ {
+		char := text[i]
+		if charErr := dev.checkChar(char); charErr != nil {
+			if err == nil {
+				err = charErr
+			}
+			continue
+		}
+		each(char, dev.kerningOffset(prev, char))
+		prev = char
+	}
+	return err
+}
+
+func (dev *sketcher) kerningOffset(prev, char byte) float64 {
+	if prev == 0 || dev.kerning == nil {
+		return 0
+	}
+	return dev.kerning[KerningPair{Left: prev, Right: char}]
+}
+
+func (dev *sketcher) charAdvance(char byte) float64 {
+	glyph := dev.bitmapFont.Glyphs[char-0x20]
+	advance := float64(glyph.XAdvance) + dev.letterSpacing
+	if char == ' ' {
+		advance += dev.wordSpacing
+	}
+	return advance
+}
@@ -0,0 +1,73 @@
+package drawings
+
+import (
+	"math"
+	"testing"
+)
+
+func textWidth(s Sketcher, text string, xscale float64) float64 {
+	x1, _, x2, _ := s.GetTextArea(0, 0, text, xscale, 1)
+	return x2 - x1
+}
+
+// cursorAdvance returns how far writing the text moves the cursor.
+func cursorAdvance(s Sketcher, text string, xscale float64) float64 {
+	s.MoveCursor(10, 40)
+	s.WriteScaled(text, xscale, 1, 0xFFFFFF)
+	return s.(*sketcher).cursorX - 10
+}
+
+func TestTextSpacing(t *testing.T) {
+	s := NewSketcher(newCountingDevice(400, 100), 0)
+	avWidth := textWidth(s, "AV", 1)
+	avAdvance := cursorAdvance(s, "AV", 1)
+	abcWidth := textWidth(s, "ABC", 1)
+	spaceWidth := textWidth(s, "A B", 1)
+	advance := s.(*sketcher).charAdvance('A')
+
+	tests := []struct {
+		name  string
+		setup func()
+		text  string
+		scale float64
+		width float64
+	}{
+		{"kerning", func() { s.SetKerning(KerningTable{{Left: 'A', Right: 'V'}: -2}) }, "AV", 1, avWidth - 2},
+		{"kerning of other pairs", func() { s.SetKerning(KerningTable{{Left: 'V', Right: 'A'}: -2}) }, "AV", 1, avWidth},
+		{"kerning across an invalid character", func() { s.SetKerning(KerningTable{{Left: 'A', Right: 'V'}: -2}) }, "A\x01V", 1, avWidth - 2},
+		{"scaled kerning", func() { s.SetKerning(KerningTable{{Left: 'A', Right: 'V'}: -2}) }, "AV", 2, 2 * (avWidth - 2)},
+		{"letter spacing", func() { s.SetLetterSpacing(3) }, "ABC", 1, abcWidth + 2*3},
+		{"negative letter spacing", func() { s.SetLetterSpacing(-1.5) }, "ABC", 1, abcWidth - 2*1.5},
+		{"word spacing", func() { s.SetWordSpacing(5) }, "A B", 1, spaceWidth + 5},
+		{"word and letter spacing", func() { s.SetWordSpacing(5); s.SetLetterSpacing(1) }, "A B", 1, spaceWidth + 5 + 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s.SetKerning(nil)
+			s.SetLetterSpacing(0)
+			s.SetWordSpacing(0)
+			test.setup()
+			if w := textWidth(s, test.text, test.scale); math.Abs(w-test.width) > 1e-9 {
+				t.Errorf("width of %q = %v, want %v", test.text, w, test.width)
+			}
+		})
+	}
+
+	// the cursor moves by the width and the spacing after the last character
+	s.SetKerning(KerningTable{{Left: 'A', Right: 'V'}: -2})
+	s.SetLetterSpacing(0)
+	s.SetWordSpacing(0)
+	if a := cursorAdvance(s, "AV", 1); math.Abs(a-(avAdvance-2)) > 1e-9 {
+		t.Errorf("kerned cursor advance = %v, want %v", a, avAdvance-2)
+	}
+	if a := cursorAdvance(s, "A\x01V", 1); math.Abs(a-(avAdvance-2)) > 1e-9 {
+		t.Errorf("cursor advance across an invalid character = %v, want %v", a, avAdvance-2)
+	}
+	if err := s.Err(); err != ErrCharOutOfRange {
+		t.Errorf("writing an invalid character reports %v", err)
+	}
+	s.SetLetterSpacing(3)
+	if a := cursorAdvance(s, "A", 2); math.Abs(a-2*(advance+3)) > 1e-9 {
+		t.Errorf("scaled cursor advance = %v, want %v", a, 2*(advance+3))
+	}
+}
@@ -0,0 +1,157 @@
+package drawings
+
+import "math"
+
+// Paint is a colour that changes over the drawing. It can be given to any drawing call in
+// place of a colour and is resolved for every pixel at its coordinates on the sketcher.
+// The pixels are drawn with the colour ColorAt returns, so it must be a type the device takes.
+type Paint interface {
+	ColorAt(x, y float64) any
+}
+
+// Solid paints every pixel with one colour of any type the device takes.
+type Solid struct {
+	Color any
+}
+
+func (s Solid) ColorAt(x, y float64) any {
+	return s.Color
+}
+
+// GradientStop is the colour at Offset, 0 the start and 1 the end of a gradient.
+// The stops of a gradient are in increasing order of their offsets.
+type GradientStop struct {
+	Offset float64
+	Color  Color
+}
+
+// LinearGradient changes along the line from (X1, Y1) to (X2, Y2) and is constant across it.
+// Pixels before the first or after the last stop take the colour of that stop.
+//
+// Dither spreads the colours between the levels of RGB565 panels with a 4×4 Bayer pattern
+// to avoid banding.
+//
+// The gradients resolve to Color, devices taking their own colour types such as the ili9341
+// driver are wrapped in a devices/converter device to draw them.
+type LinearGradient struct {
+	X1     float64
+	Y1     float64
+	X2     float64
+	Y2     float64
+	Stops  []GradientStop
+	Dither bool
+}
+
+// RadialGradient changes from the centre (X, Y) to the circle of the radius. Like
+// LinearGradient it resolves to Color.
+type RadialGradient struct {
+	X      float64
+	Y      float64
+	Radius float64
+	Stops  []GradientStop
+	Dither bool
+}
+
+// bayer is the 4×4 ordered dithering matrix.
+var bayer = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+func (g LinearGradient) ColorAt(x, y float64) any {
+	dx := g.X2 - g.X1
+	dy := g.Y2 - g.Y1
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = ((x-g.X1)*dx + (y-g.Y1)*dy) / length
+	}
+	return gradientColor(g.Stops, t, g.Dither, x, y)
+}
+
+func (g RadialGradient) ColorAt(x, y float64) any {
+	t := 0.0
+	if g.Radius > 0 {
+		t = math.Hypot(x-g.X, y-g.Y) / g.Radius
+	}
+	return gradientColor(g.Stops, t, g.Dither, x, y)
+}
+
+// gradientColor returns the colour at t of the stops, dithered for the pixel at x, y.
+func gradientColor(stops []GradientStop, t float64, dither bool, x, y float64) Color {
+	if len(stops) == 0 {
+		return Color{}
+	}
+	from, to := stops[0], stops[0]
+	f := 0.0
+	if t >= stops[len(stops)-1].Offset {
+		from, to = stops[len(stops)-1], stops[len(stops)-1]
+	} else if t > stops[0].Offset {
+		for i := 1; i < len(stops); i++ {
+			if t < stops[i].Offset {
+				from, to = stops[i-1], stops[i]
+				f = (t - from.Offset) / (to.Offset - from.Offset)
+				break
+			}
+		}
+	}
+	offset := 0.5
+	if dither {
+		offset = (bayer[int(y)&3][int(x)&3] + 0.5) / 16
+	}
+	channel := func(a, b uint8, step float64) uint8 {
+		v := float64(a) + (float64(b)-float64(a))*f
+		if dither {
+			v += offset * step
+		} else {
+			v += offset
+		}
+		return uint8(math.Max(0, math.Min(255, math.Floor(v))))
+	}
+	return Color{
+		R: channel(from.Color.R, to.Color.R, 8),
+		G: channel(from.Color.G, to.Color.G, 4),
+		B: channel(from.Color.B, to.Color.B, 8),
+	}
+}
+
+// paintAt resolves a Paint at the pixel, any other colour is returned as it is.
+func paintAt(color any, x, y float64) any {
+	if p, ok := color.(Paint); ok {
+		return p.ColorAt(x, y)
+	}
+	return color
+}
+
+// FillRectangleGradient fills the rectangle with a gradient from the first to the second
+// colour, from left to right or from top to bottom when vertical. The pixels are drawn with
+// Color like the gradient paints.
+func (dev *sketcher) FillRectangleGradient(x1, y1, x2, y2 float64, from, to any, vertical bool) {
+	g, err := RectangleGradient(x1, y1, x2, y2, from, to, vertical)
+	if err != nil {
+		dev.setErr(err)
+		return
+	}
+	dev.FillRectangle(x1, y1, x2, y2, g)
+}
+
+// RectangleGradient returns the gradient of FillRectangleGradient, the colours are anything
+// ToColor takes.
+func RectangleGradient(x1, y1, x2, y2 float64, from, to any, vertical bool) (LinearGradient, error) {
+	start, err := ToColor(from)
+	if err != nil {
+		return LinearGradient{}, err
+	}
+	end, err := ToColor(to)
+	if err != nil {
+		return LinearGradient{}, err
+	}
+	g := LinearGradient{X1: x1, Y1: y1, X2: x2, Y2: y1}
+	if vertical {
+		g.X2 = x1
+		g.Y2 = y2
+	}
+	g.Stops = []GradientStop{{Offset: 0, Color: start}, {Offset: 1, Color: end}}
+	return g, nil
+}
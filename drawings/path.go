@@ -0,0 +1,237 @@
+package drawings
+
+import "math"
+
+type FillRule int
+type PathOp int
+
+const (
+	NON_ZERO FillRule = 0
+	EVEN_ODD FillRule = 1
+)
+
+const (
+	MOVE_TO  PathOp = 0
+	LINE_TO  PathOp = 1
+	QUAD_TO  PathOp = 2
+	CUBIC_TO PathOp = 3
+	CLOSE    PathOp = 4
+)
+
+// PathSegment is one command of a path with its control and end points.
+type PathSegment struct {
+	Op     PathOp
+	Points []Point
+}
+
+// Path is a shape made of lines and curves, built like an SVG path. Arcs are stored as cubic curves.
+type Path struct {
+	segments []PathSegment
+	current  Point
+	start    Point
+}
+
+func NewPath() *Path {
+	return &Path{
+		segments: make([]PathSegment, 0),
+	}
+}
+
+func (p *Path) add(op PathOp, points ...Point) *Path {
+	p.segments = append(p.segments, PathSegment{Op: op, Points: points})
+	if len(points) > 0 {
+		p.current = points[len(points)-1]
+	}
+	return p
+}
+
+// ensureStart starts a subpath at the current point when a drawing command comes first.
+func (p *Path) ensureStart() {
+	if len(p.segments) == 0 || p.segments[len(p.segments)-1].Op == CLOSE {
+		p.MoveTo(p.current.X, p.current.Y)
+	}
+}
+
+func (p *Path) MoveTo(x, y float64) *Path {
+	p.start = Point{x, y}
+	return p.add(MOVE_TO, Point{x, y})
+}
+
+func (p *Path) LineTo(x, y float64) *Path {
+	p.ensureStart()
+	return p.add(LINE_TO, Point{x, y})
+}
+
+func (p *Path) QuadTo(cx, cy, x, y float64) *Path {
+	p.ensureStart()
+	return p.add(QUAD_TO, Point{cx, cy}, Point{x, y})
+}
+
+func (p *Path) CubicTo(cx1, cy1, cx2, cy2, x, y float64) *Path {
+	p.ensureStart()
+	return p.add(CUBIC_TO, Point{cx1, cy1}, Point{cx2, cy2}, Point{x, y})
+}
+
+// ArcTo adds an elliptical arc to (x, y) with the parameters of the SVG arc command, the
+// rotation of the ellipse is in radians.
+func (p *Path) ArcTo(rx, ry, xAxisRotation float64, largeArc, sweep bool, x, y float64) *Path {
+	p.ensureStart()
+	x0, y0 := p.current.X, p.current.Y
+	rx = math.Abs(rx)
+	ry = math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return p.LineTo(x, y)
+	}
+	if x0 == x && y0 == y {
+		return p
+	}
+
+	// endpoint to centre parameterization https://www.w3.org/TR/SVG11/implnote.html#ArcImplementationNotes
+	sinPhi, cosPhi := math.Sincos(xAxisRotation)
+	hx := (x0 - x) / 2
+	hy := (y0 - y) / 2
+	x1 := cosPhi*hx + sinPhi*hy
+	y1 := -sinPhi*hx + cosPhi*hy
+	lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry)
+	if lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (x0+x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (y0+y)/2
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += DEG360
+	} else if !sweep && delta > 0 {
+		delta -= DEG360
+	}
+
+	// one cubic curve for every quarter of the ellipse
+	n := int(math.Ceil(math.Abs(delta) / DEG90))
+	step := delta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	toPath := func(ux, uy float64) (float64, float64) {
+		return cx + rx*cosPhi*ux - ry*sinPhi*uy, cy + rx*sinPhi*ux + ry*cosPhi*uy
+	}
+	for i := 0; i < n; i++ {
+		t1 := theta + float64(i)*step
+		t2 := t1 + step
+		sin1, cos1 := math.Sincos(t1)
+		sin2, cos2 := math.Sincos(t2)
+		c1x, c1y := toPath(cos1-k*sin1, sin1+k*cos1)
+		c2x, c2y := toPath(cos2+k*sin2, sin2-k*cos2)
+		ex, ey := toPath(cos2, sin2)
+		if i == n-1 {
+			ex, ey = x, y
+		}
+		p.CubicTo(c1x, c1y, c2x, c2y, ex, ey)
+	}
+	return p
+}
+
+func (p *Path) Close() *Path {
+	if len(p.segments) == 0 {
+		return p
+	}
+	p.add(CLOSE)
+	p.current = p.start
+	return p
+}
+
+// Segments returns the commands of the path.
+func (p *Path) Segments() []PathSegment {
+	segments := make([]PathSegment, len(p.segments))
+	copy(segments, p.segments)
+	return segments
+}
+
+// Transform returns a copy of the path with every point mapped by the affine matrix
+// [a c e; b d f], the same as the SVG matrix(a, b, c, d, e, f).
+func (p *Path) Transform(a, b, c, d, e, f float64) *Path {
+	t := NewPath()
+	for _, s := range p.segments {
+		points := make([]Point, len(s.Points))
+		for i, pt := range s.Points {
+			points[i] = Point{a*pt.X + c*pt.Y + e, b*pt.X + d*pt.Y + f}
+		}
+		if s.Op == MOVE_TO {
+			t.start = points[0]
+		}
+		t.add(s.Op, points...)
+		if s.Op == CLOSE {
+			t.current = t.start
+		}
+	}
+	return t
+}
+
+// flatten approximates the path with lines, one list of points for every subpath.
+func (p *Path) flatten() (subpaths [][]Point, closed []bool) {
+	subpaths = make([][]Point, 0)
+	closed = make([]bool, 0)
+	current := Point{}
+	for _, s := range p.segments {
+		if s.Op == MOVE_TO {
+			subpaths = append(subpaths, []Point{s.Points[0]})
+			closed = append(closed, false)
+			current = s.Points[0]
+			continue
+		}
+		if len(subpaths) == 0 {
+			continue
+		}
+		last := len(subpaths) - 1
+		switch s.Op {
+		case LINE_TO:
+			subpaths[last] = append(subpaths[last], s.Points[0])
+		case QUAD_TO:
+			subpaths[last] = flattenQuadBezier(subpaths[last], current, s.Points[0], s.Points[1], 0)
+		case CUBIC_TO:
+			subpaths[last] = flattenCubicBezier(subpaths[last], current, s.Points[0], s.Points[1], s.Points[2], 0)
+		case CLOSE:
+			closed[last] = true
+			current = subpaths[last][0]
+			continue
+		}
+		current = s.Points[len(s.Points)-1]
+	}
+	return subpaths, closed
+}
+
+// StrokePath draws the outline of the path, width wide with round joins and ends.
+// Widths of one pixel or less are drawn with Line.
+func (dev *sketcher) StrokePath(path *Path, width float64, color any) {
+	defer dev.commitDirty()
+	subpaths, closed := path.flatten()
+	if width <= 1 {
+		for i, points := range subpaths {
+			if closed[i] && len(points) > 1 {
+				points = append(points, points[0])
+			}
+			dev.Polyline(points, color)
+		}
+		return
+	}
+	polygons := make([][]Point, 0)
+	for i, points := range subpaths {
+		polygons = append(polygons, strokePolygons(points, closed[i], width)...)
+	}
+	dev.fillPolygons(polygons, NON_ZERO, color)
+}
+
+// FillPath fills the inside of the path, every subpath is closed with a line.
+func (dev *sketcher) FillPath(path *Path, rule FillRule, color any) {
+	defer dev.commitDirty()
+	subpaths, _ := path.flatten()
+	dev.fillPolygons(subpaths, rule, color)
+}
@@ -0,0 +1,67 @@
+package drawings
+
+import (
+	"math"
+	"testing"
+)
+
+func pentagram(xc, yc, radius float64) *Path {
+	p := NewPath()
+	for i := 0; i < 5; i++ {
+		angle := -math.Pi/2 + float64(i)*4*math.Pi/5
+		x, y := xc+radius*math.Cos(angle), yc+radius*math.Sin(angle)
+		if i == 0 {
+			p.MoveTo(x, y)
+		} else {
+			p.LineTo(x, y)
+		}
+	}
+	return p.Close()
+}
+
+// square adds a square going clockwise on the screen, or counter-clockwise when reversed.
+func square(p *Path, x1, y1, x2, y2 float64, reversed bool) *Path {
+	if reversed {
+		return p.MoveTo(x1, y1).LineTo(x1, y2).LineTo(x2, y2).LineTo(x2, y1).Close()
+	}
+	return p.MoveTo(x1, y1).LineTo(x2, y1).LineTo(x2, y2).LineTo(x1, y2).Close()
+}
+
+func TestFillRules(t *testing.T) {
+	tests := []struct {
+		name string
+		path *Path
+		// outside is a pixel in the shape outside of the overlap, inside one in the overlap
+		outside, inside [2]int
+		// holeNonZero tells if NON_ZERO leaves the overlap empty as well
+		holeNonZero bool
+	}{
+		{"pentagram", pentagram(20, 15, 12), [2]int{20, 6}, [2]int{20, 15}, false},
+		{"same direction squares", square(square(NewPath(), 4, 2, 36, 28, false), 14, 10, 26, 20, false), [2]int{6, 5}, [2]int{20, 15}, false},
+		{"opposite direction squares", square(square(NewPath(), 4, 2, 36, 28, false), 14, 10, 26, 20, true), [2]int{6, 5}, [2]int{20, 15}, true},
+	}
+	dev := newCountingDevice(40, 30)
+	s := NewSketcher(dev, 0)
+	for _, test := range tests {
+		for _, rule := range []FillRule{NON_ZERO, EVEN_ODD} {
+			counts := dev.drawn(func() { s.FillPath(test.path, rule, 1) })
+			at := func(p [2]int) int { return counts[p[1]*dev.width+p[0]] }
+			for i, n := range counts {
+				if n > 1 {
+					t.Errorf("%s rule %d: pixel %d, %d drawn %d times", test.name, rule, i%dev.width, i/dev.width, n)
+					break
+				}
+			}
+			if at(test.outside) != 1 {
+				t.Errorf("%s rule %d: %v is not filled", test.name, rule, test.outside)
+			}
+			wantInside := 1
+			if rule == EVEN_ODD || test.holeNonZero {
+				wantInside = 0
+			}
+			if got := at(test.inside); got != wantInside {
+				t.Errorf("%s rule %d: %v drawn %d times, want %d", test.name, rule, test.inside, got, wantInside)
+			}
+		}
+	}
+}
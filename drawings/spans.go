@@ -0,0 +1,123 @@
+package drawings
+
+import "math"
+
+// hspan draws the row y from x1 to x2, both included. The rotation is applied once for
+// the whole span and the part outside of the device is skipped. A Paint is resolved for
+// every pixel.
+func (d *sketcher) hspan(x1, x2, y int, color any) {
+	if x2 < x1 {
+		x1, x2 = x2, x1
+	}
+	fx, fy := d.rotatePoint(float64(x1), float64(y))
+	nx, ny := d.rotatePoint(float64(x1+1), float64(y))
+	sx, sy := int(fx), int(fy)
+	stepX, stepY := int(nx)-sx, int(ny)-sy
+
+	from, to := 0, x2-x1
+	var ok bool
+	if from, to, ok = clipSpan(sx, stepX, d.pixeldev.ScreenWidth(), from, to); !ok {
+		return
+	}
+	if from, to, ok = clipSpan(sy, stepY, d.pixeldev.ScreenHeight(), from, to); !ok {
+		return
+	}
+	paint, isPaint := color.(Paint)
+	for t := from; t <= to; t++ {
+		if isPaint {
+			color = paint.ColorAt(float64(x1+t), float64(y))
+		}
+		d.setErr(d.pixeldev.Pixel(sx+t*stepX, sy+t*stepY, color))
+	}
+	d.markDirty(sx+from*stepX, sy+from*stepY)
+	d.markDirty(sx+to*stepX, sy+to*stepY)
+}
+
+// clipSpan limits the steps from..to of start+t*step to 0..size-1.
+func clipSpan(start, step, size, from, to int) (int, int, bool) {
+	if step == 0 {
+		return from, to, start >= 0 && start < size
+	}
+	lo, hi := -start, size-1-start
+	if step < 0 {
+		lo, hi = start-(size-1), start
+	}
+	if lo > from {
+		from = lo
+	}
+	if hi < to {
+		to = hi
+	}
+	return from, to, from <= to
+}
+
+// clipRange limits the whole pixels from..to to 0..size-1, from is greater than to when
+// nothing is left. Infinite and NaN coordinates are clipped before they become ints.
+func clipRange(from, to, size float64) (int, int) {
+	if math.IsNaN(from) || math.IsNaN(to) {
+		return 0, -1
+	}
+	from = math.Max(from, 0)
+	to = math.Min(to, size-1)
+	if from > to {
+		return 0, -1
+	}
+	return int(from), int(to)
+}
+
+// fillAnnulus fills the pixels whose centre is farther than ri and not farther than ro from (xc, yc).
+// When inside is given, only the pixels it accepts for their offset from the centre are drawn.
+func (d *sketcher) fillAnnulus(xc, yc, ri, ro float64, inside func(dx, dy float64) bool, color any) {
+	if ro <= 0 || ro <= ri {
+		return
+	}
+	span := func(left, right float64, y int) {
+		x1, x2 := clipRange(left, right, d.ScreenWidth())
+		if x1 > x2 {
+			return
+		}
+		if inside == nil {
+			d.hspan(x1, x2, y, color)
+			return
+		}
+		from := x1
+		for x := x1; x <= x2+1; x++ {
+			if x <= x2 && inside(float64(x)-xc, float64(y)-yc) {
+				continue
+			}
+			if x > from {
+				d.hspan(from, x-1, y, color)
+			}
+			from = x + 1
+		}
+	}
+	ys, ye := clipRange(math.Ceil(yc-ro), math.Floor(yc+ro), d.ScreenHeight())
+	for y := ys; y <= ye; y++ {
+		dy := float64(y) - yc
+		xo := math.Sqrt(ro*ro - dy*dy)
+		left := math.Ceil(xc - xo)
+		right := math.Floor(xc + xo)
+		if ri <= 0 || math.Abs(dy) > ri {
+			span(left, right, y)
+			continue
+		}
+		xi := math.Sqrt(ri*ri - dy*dy)
+		span(left, math.Ceil(xc-xi)-1, y)
+		span(math.Floor(xc+xi)+1, right, y)
+	}
+}
+
+// isAngleInArc tells if angle is on the arc drawn from startAngle to endAngle in increasing angles.
+func isAngleInArc(angle, startAngle, endAngle float64) bool {
+	sweep := normalizeAngle(endAngle - startAngle)
+	return normalizeAngle(angle-startAngle) <= sweep
+}
+
+// normalizeAngle returns the angle in 0..2π.
+func normalizeAngle(angle float64) float64 {
+	angle = math.Mod(angle, DEG360)
+	if angle < 0 {
+		angle += DEG360
+	}
+	return angle
+}
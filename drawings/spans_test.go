@@ -0,0 +1,87 @@
+package drawings
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestHugeShapesAreClipped draws shapes far larger than the screen, every screen pixel is
+// drawn once and the rows and columns outside of the screen are not visited.
+func TestHugeShapesAreClipped(t *testing.T) {
+	const huge = 1e12
+	shapes := map[string]func(s Sketcher){
+		"ClearArea":     func(s Sketcher) { s.ClearArea(-huge, -huge, huge, huge, 1) },
+		"FillRectangle": func(s Sketcher) { s.FillRectangle(huge, huge, -huge, -huge, 1) },
+		"FillCircle":    func(s Sketcher) { s.FillCircle(20, 15, huge, 1) },
+		"ThickCircle":   func(s Sketcher) { s.ThickCircle(20, 15, huge, huge, INNER_WIDTH, 1) },
+		"ThickArc":      func(s Sketcher) { s.ThickArc(20, 15, huge, 0, DEG360-0.001, huge, INNER_WIDTH, 1) },
+		"FillPolygon": func(s Sketcher) {
+			s.FillPolygon([]Point{{-huge, -huge}, {huge, -huge}, {huge, huge}, {-huge, huge}}, 1)
+		},
+	}
+	for name, draw := range shapes {
+		for rotation := ROTATION_0; rotation <= ROTATION_270; rotation++ {
+			dev := newCountingDevice(40, 30)
+			s := NewSketcher(dev, 0)
+			s.SetRotation(float64(rotation))
+			start := time.Now()
+			draw(s)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("%s in rotation %d took %v", name, rotation, elapsed)
+			}
+			for i, n := range dev.counts {
+				if n != 1 {
+					t.Errorf("%s in rotation %d drew pixel %d, %d %d times", name, rotation, i%40, i/40, n)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNaNShapesDrawNothing(t *testing.T) {
+	dev := newCountingDevice(40, 30)
+	s := NewSketcher(dev, 0)
+	s.FillRectangle(math.NaN(), 0, 10, 10, 1)
+	s.ClearArea(0, 0, 10, math.NaN(), 1)
+	s.FillCircle(10, 10, math.NaN(), 1)
+	s.FillCircle(10, 10, math.Inf(1), 1)
+	s.FillCircle(math.NaN(), 10, 5, 1)
+	s.FillCircle(10, math.Inf(-1), 5, 1)
+	s.FillPolygon([]Point{{0, 0}, {10, math.NaN()}, {0, 10}}, 1)
+	for i, n := range dev.counts {
+		if n != 0 {
+			t.Fatalf("pixel %d, %d drawn", i%40, i/40)
+		}
+	}
+}
+
+// BenchmarkFillRectangleSpans and BenchmarkFillRectanglePixels compare filling with
+// horizontal spans to drawing the same pixels one by one.
+func BenchmarkFillRectangleSpans(b *testing.B) {
+	s := NewSketcher(newCountingDevice(320, 240), 0)
+	s.SetRotation(ROTATION_90)
+	for i := 0; i < b.N; i++ {
+		s.FillRectangle(0, 0, 239, 319, 1)
+	}
+}
+
+func BenchmarkFillRectanglePixels(b *testing.B) {
+	s := NewSketcher(newCountingDevice(320, 240), 0)
+	s.SetRotation(ROTATION_90)
+	for i := 0; i < b.N; i++ {
+		for y := 0.0; y < 320; y++ {
+			for x := 0.0; x < 240; x++ {
+				s.Pixel(x, y, 1)
+			}
+		}
+	}
+}
+
+func BenchmarkFillRectangleOffscreen(b *testing.B) {
+	s := NewSketcher(newCountingDevice(320, 240), 0)
+	for i := 0; i < b.N; i++ {
+		s.FillRectangle(-1e12, -1e12, 1e12, 1e12, 1)
+	}
+}
@@ -0,0 +1,120 @@
+package fontfile
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+// LoadBDF reads the printable ASCII characters of a Glyph Bitmap Distribution Format font.
+func LoadBDF(r io.Reader) (*Font, error) {
+	scanner := bufio.NewScanner(r)
+	bitmap := make([]byte, 0)
+	glyphs := make(map[byte]fonts.Glyph)
+
+	var defaultBox [4]int
+	var box [4]int
+	encoding := -1
+	xAdvance := 0
+	inBitmap := false
+	rows := make([][]byte, 0)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if inBitmap && fields[0] != "ENDCHAR" {
+			row, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNumber, err)
+			}
+			rows = append(rows, row)
+			continue
+		}
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if err := parseInts(fields[1:], defaultBox[:]); err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNumber, err)
+			}
+		case "STARTCHAR":
+			box = defaultBox
+			encoding = -1
+			xAdvance = defaultBox[0]
+			rows = rows[:0]
+		case "ENCODING":
+			v := []int{0}
+			if err := parseInts(fields[1:], v); err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNumber, err)
+			}
+			encoding = v[0]
+		case "DWIDTH":
+			v := []int{0}
+			if err := parseInts(fields[1:], v); err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNumber, err)
+			}
+			xAdvance = v[0]
+		case "BBX":
+			if err := parseInts(fields[1:], box[:]); err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNumber, err)
+			}
+		case "BITMAP":
+			inBitmap = true
+		case "ENDCHAR":
+			inBitmap = false
+			if encoding < int(FIRST_CHAR) || encoding > int(LAST_CHAR) {
+				continue
+			}
+			width, height := box[0], box[1]
+			glyphs[byte(encoding)] = fonts.Glyph{
+				BitmapOffset: len(bitmap),
+				Width:        width,
+				Height:       height,
+				XAdvance:     xAdvance,
+				XOffset:      box[2],
+				YOffset:      -(box[3] + height),
+			}
+			bitmap = append(bitmap, packRows(rows, width, height)...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newFont(bitmap, glyphs, nil)
+}
+
+func parseInts(fields []string, values []int) error {
+	if len(fields) < len(values) {
+		return fmt.Errorf("expected %d numbers", len(values))
+	}
+	for i := range values {
+		v, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	return nil
+}
+
+// packRows converts byte padded BDF rows into the continuous bit stream used by GFX glyphs.
+func packRows(rows [][]byte, width, height int) []byte {
+	packed := make([]byte, (width*height+7)/8)
+	for h := 0; h < height && h < len(rows); h++ {
+		for w := 0; w < width && w/8 < len(rows[h]); w++ {
+			if rows[h][w/8]&(0b10000000>>(w%8)) == 0 {
+				continue
+			}
+			bitIndex := h*width + w
+			packed[bitIndex/8] |= 0b10000000 >> (bitIndex % 8)
+		}
+	}
+	return packed
+}
@@ -0,0 +1,253 @@
+package fontfile
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+const (
+	FIRST_CHAR  byte = 0x20
+	LAST_CHAR   byte = 0x7E
+	GFX_VERSION byte = 1
+)
+
+var gfxMagic = [4]byte{'G', 'F', 'X', 'F'}
+
+// maxGlyphBitmap is the size of the bitmap of the largest glyph, its width and height are bytes.
+const maxGlyphBitmap = (255*255 + 7) / 8
+
+// Font is a bitmap font loaded at runtime together with the kerning pairs stored next to it.
+type Font struct {
+	BitmapFont fonts.BitmapFont
+	Kerning    drawings.KerningTable
+}
+
+// Load reads a font from fsys choosing the format by the file extension:
+// .gfx for binary GFX fonts, .json for JSON GFX fonts and .bdf for BDF fonts.
+func Load(fsys fs.FS, name string) (*Font, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".gfx":
+		return LoadGFX(f)
+	case ".json":
+		return LoadJSON(f)
+	case ".bdf":
+		return LoadBDF(f)
+	}
+	return nil, fmt.Errorf("font format of %q is not supported", name)
+}
+
+// LoadGFX reads the binary GFX format written by EncodeGFX:
+//
+//	magic "GFXF", version, first char, last char, reserved byte
+//	bitmap length (uint32), bitmap
+//	per glyph: bitmap offset (uint32), width, height, x advance (uint8), x offset, y offset (int8)
+//
+// All numbers are little endian.
+func LoadGFX(r io.Reader) (*Font, error) {
+	var header struct {
+		Magic     [4]byte
+		Version   byte
+		First     byte
+		Last      byte
+		Reserved  byte
+		BitmapLen uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != gfxMagic {
+		return nil, errors.New("not a gfx font file")
+	}
+	if header.Version != GFX_VERSION {
+		return nil, fmt.Errorf("gfx font version %d is not supported", header.Version)
+	}
+	if header.Last < header.First {
+		return nil, errors.New("gfx font has no glyphs")
+	}
+	// the glyph table cannot address more, a larger length is a corrupt file
+	if int64(header.BitmapLen) > int64(int(header.Last)-int(header.First)+1)*maxGlyphBitmap {
+		return nil, fmt.Errorf("gfx font bitmap length %d is too large", header.BitmapLen)
+	}
+	bitmap := make([]byte, header.BitmapLen)
+	if _, err := io.ReadFull(r, bitmap); err != nil {
+		return nil, err
+	}
+	glyphs := make(map[byte]fonts.Glyph)
+	for char := int(header.First); char <= int(header.Last); char++ {
+		var g struct {
+			BitmapOffset uint32
+			Width        uint8
+			Height       uint8
+			XAdvance     uint8
+			XOffset      int8
+			YOffset      int8
+		}
+		if err := binary.Read(r, binary.LittleEndian, &g); err != nil {
+			return nil, err
+		}
+		glyphs[byte(char)] = fonts.Glyph{
+			BitmapOffset: int(g.BitmapOffset),
+			Width:        int(g.Width),
+			Height:       int(g.Height),
+			XAdvance:     int(g.XAdvance),
+			XOffset:      int(g.XOffset),
+			YOffset:      int(g.YOffset),
+		}
+	}
+	return newFont(bitmap, glyphs, nil)
+}
+
+// EncodeGFX writes the printable characters of font in the binary format read by LoadGFX.
+func EncodeGFX(w io.Writer, font fonts.BitmapFont) error {
+	if len(font.Glyphs) < int(LAST_CHAR-FIRST_CHAR)+1 {
+		return errors.New("font does not cover the printable characters")
+	}
+	header := []any{gfxMagic, GFX_VERSION, FIRST_CHAR, LAST_CHAR, byte(0), uint32(len(font.Bitmap)), font.Bitmap}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	for char := FIRST_CHAR; char <= LAST_CHAR; char++ {
+		g := font.Glyphs[char-FIRST_CHAR]
+		fields := []any{uint32(g.BitmapOffset), uint8(g.Width), uint8(g.Height), uint8(g.XAdvance), int8(g.XOffset), int8(g.YOffset)}
+		for _, v := range fields {
+			if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type jsonGlyph struct {
+	Char         string `json:"char"`
+	BitmapOffset int    `json:"bitmapOffset"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	XAdvance     int    `json:"xAdvance"`
+	XOffset      int    `json:"xOffset"`
+	YOffset      int    `json:"yOffset"`
+}
+
+type jsonKerning struct {
+	Left       string  `json:"left"`
+	Right      string  `json:"right"`
+	Adjustment float64 `json:"adjustment"`
+}
+
+type jsonFont struct {
+	Bitmap  []byte        `json:"bitmap"`
+	Glyphs  []jsonGlyph   `json:"glyphs"`
+	Kerning []jsonKerning `json:"kerning,omitempty"`
+}
+
+// LoadJSON reads a GFX font stored as JSON. The bitmap is base64 encoded and every glyph
+// names the character it draws, so fonts may leave characters out.
+func LoadJSON(r io.Reader) (*Font, error) {
+	var jf jsonFont
+	if err := json.NewDecoder(r).Decode(&jf); err != nil {
+		return nil, err
+	}
+	glyphs := make(map[byte]fonts.Glyph)
+	for _, g := range jf.Glyphs {
+		if len(g.Char) != 1 {
+			return nil, fmt.Errorf("invalid glyph character %q", g.Char)
+		}
+		glyphs[g.Char[0]] = fonts.Glyph{
+			BitmapOffset: g.BitmapOffset,
+			Width:        g.Width,
+			Height:       g.Height,
+			XAdvance:     g.XAdvance,
+			XOffset:      g.XOffset,
+			YOffset:      g.YOffset,
+		}
+	}
+	var kerning drawings.KerningTable = nil
+	if len(jf.Kerning) > 0 {
+		kerning = make(drawings.KerningTable)
+		for _, k := range jf.Kerning {
+			if len(k.Left) != 1 || len(k.Right) != 1 {
+				return nil, fmt.Errorf("invalid kerning pair %q %q", k.Left, k.Right)
+			}
+			kerning[drawings.KerningPair{Left: k.Left[0], Right: k.Right[0]}] = k.Adjustment
+		}
+	}
+	return newFont(jf.Bitmap, glyphs, kerning)
+}
+
+// EncodeJSON writes the printable characters of font in the format read by LoadJSON.
+func EncodeJSON(w io.Writer, font fonts.BitmapFont, kerning drawings.KerningTable) error {
+	if len(font.Glyphs) < int(LAST_CHAR-FIRST_CHAR)+1 {
+		return errors.New("font does not cover the printable characters")
+	}
+	jf := jsonFont{
+		Bitmap: font.Bitmap,
+		Glyphs: make([]jsonGlyph, 0, LAST_CHAR-FIRST_CHAR+1),
+	}
+	for char := FIRST_CHAR; char <= LAST_CHAR; char++ {
+		g := font.Glyphs[char-FIRST_CHAR]
+		jf.Glyphs = append(jf.Glyphs, jsonGlyph{
+			Char:         string([]byte{char}),
+			BitmapOffset: g.BitmapOffset,
+			Width:        g.Width,
+			Height:       g.Height,
+			XAdvance:     g.XAdvance,
+			XOffset:      g.XOffset,
+			YOffset:      g.YOffset,
+		})
+	}
+	for pair, adjustment := range kerning {
+		jf.Kerning = append(jf.Kerning, jsonKerning{
+			Left:       string([]byte{pair.Left}),
+			Right:      string([]byte{pair.Right}),
+			Adjustment: adjustment,
+		})
+	}
+	return json.NewEncoder(w).Encode(jf)
+}
+
+// newFont lays the glyphs out the way the sketcher indexes them, one per printable character.
+// Characters missing from the font are drawn as empty glyphs as wide as a space.
+func newFont(bitmap []byte, glyphs map[byte]fonts.Glyph, kerning drawings.KerningTable) (*Font, error) {
+	if len(glyphs) == 0 {
+		return nil, errors.New("font has no glyphs")
+	}
+	missing := fonts.Glyph{}
+	if space, ok := glyphs[' ']; ok {
+		missing.XAdvance = space.XAdvance
+	}
+	font := &Font{
+		BitmapFont: fonts.BitmapFont{
+			Bitmap: bitmap,
+			Glyphs: make([]fonts.Glyph, 0, LAST_CHAR-FIRST_CHAR+1),
+		},
+		Kerning: kerning,
+	}
+	for char := FIRST_CHAR; char <= LAST_CHAR; char++ {
+		g, ok := glyphs[char]
+		if !ok {
+			g = missing
+		}
+		if g.Width < 0 || g.Height < 0 || g.BitmapOffset < 0 || g.BitmapOffset+(g.Width*g.Height+7)/8 > len(bitmap) {
+			return nil, fmt.Errorf("glyph %q is outside of the bitmap", char)
+		}
+		font.BitmapFont.Glyphs = append(font.BitmapFont.Glyphs, g)
+	}
+	return font, nil
+}
@@ -0,0 +1,84 @@
+package fontfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+func TestGFXRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeGFX(&buf, fonts.FreeMono12pt7b); err != nil {
+		t.Fatal(err)
+	}
+	font, err := LoadGFX(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fonts.FreeMono12pt7b.Glyphs[:LAST_CHAR-FIRST_CHAR+1]
+	if !reflect.DeepEqual(font.BitmapFont.Glyphs, want) {
+		t.Error("glyphs differ after the round trip")
+	}
+	if !bytes.Equal(font.BitmapFont.Bitmap, fonts.FreeMono12pt7b.Bitmap) {
+		t.Error("bitmap differs after the round trip")
+	}
+}
+
+func TestLoadGFXBitmapLength(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(gfxMagic[:])
+	buf.Write([]byte{GFX_VERSION, 'A', 'A', 0})
+	binary.Write(&buf, binary.LittleEndian, uint32(0xFFFFFFF0))
+	if _, err := LoadGFX(&buf); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("got error %v, want a too large bitmap", err)
+	}
+}
+
+func TestLoadBDFMissingNumbers(t *testing.T) {
+	for _, line := range []string{"ENCODING", "DWIDTH", "BBX 1 2", "FONTBOUNDINGBOX 8"} {
+		bdf := "STARTFONT 2.1\nFONTBOUNDINGBOX 8 8 0 0\nSTARTCHAR A\n" + line + "\nBITMAP\nFF\nENDCHAR\n"
+		if _, err := LoadBDF(strings.NewReader(bdf)); err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
+
+func TestLoadBDF(t *testing.T) {
+	bdf := `STARTFONT 2.1
+FONTBOUNDINGBOX 8 8 0 -1
+STARTCHAR space
+ENCODING 32
+DWIDTH 6 0
+BBX 0 0 0 0
+BITMAP
+ENDCHAR
+STARTCHAR A
+ENCODING 65
+DWIDTH 7 0
+BBX 3 2 1 -1
+BITMAP
+A0
+40
+ENDCHAR
+ENDFONT
+`
+	font, err := LoadBDF(strings.NewReader(bdf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := font.BitmapFont.Glyphs['A'-FIRST_CHAR]
+	want := fonts.Glyph{BitmapOffset: 0, Width: 3, Height: 2, XAdvance: 7, XOffset: 1, YOffset: -1}
+	if a != want {
+		t.Errorf("glyph A = %+v, want %+v", a, want)
+	}
+	if got := font.BitmapFont.Bitmap[a.BitmapOffset]; got != 0b10101000 {
+		t.Errorf("bitmap of A = %08b, want 10101000", got)
+	}
+	if x := font.BitmapFont.Glyphs['B'-FIRST_CHAR].XAdvance; x != 6 {
+		t.Errorf("missing glyph advance = %d, want the space advance 6", x)
+	}
+}
@@ -0,0 +1,401 @@
+package fontfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// LoadKerning reads a kerning table stored as text, one pair per line:
+//
+//	# left right adjustment
+//	A V -2
+//	0x20 T -1
+//
+// Characters are given either literally or as hex codes.
+func LoadKerning(r io.Reader) (drawings.KerningTable, error) {
+	kerning := make(drawings.KerningTable)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("kerning line %d: expected 3 fields, got %d", lineNumber, len(fields))
+		}
+		left, err := parseKerningChar(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("kerning line %d: %v", lineNumber, err)
+		}
+		right, err := parseKerningChar(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("kerning line %d: %v", lineNumber, err)
+		}
+		adjustment, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("kerning line %d: %v", lineNumber, err)
+		}
+		kerning[drawings.KerningPair{Left: left, Right: right}] = adjustment
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return kerning, nil
+}
+
+func parseKerningChar(field string) (byte, error) {
+	if len(field) == 1 {
+		return field[0], nil
+	}
+	if strings.HasPrefix(field, "0x") || strings.HasPrefix(field, "0X") {
+		code, err := strconv.ParseUint(field[2:], 16, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid character code %q", field)
+		}
+		return byte(code), nil
+	}
+	return 0, fmt.Errorf("invalid character %q", field)
+}
+
+// LoadTTFKerning reads the horizontal kerning of the printable ASCII characters from the pair
+// adjustments of the 'kern' feature in the GPOS table, or from the format 0 pairs of the 'kern'
+// table when the font has no GPOS table. pixelsPerEm is the size the bitmap font was rendered
+// at, e.g. 18pt at 141dpi gives 18*141/72 pixels per em.
+func LoadTTFKerning(r io.Reader, pixelsPerEm float64) (drawings.KerningTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	font := ttfData(data)
+	tables, err := font.tables()
+	if err != nil {
+		return nil, err
+	}
+	head, ok := tables["head"]
+	if !ok || len(head) < 20 {
+		return nil, fmt.Errorf("ttf 'head' table is missing")
+	}
+	unitsPerEm := float64(ttfData(head).u16(18))
+	if unitsPerEm == 0 {
+		return nil, fmt.Errorf("ttf units per em is zero")
+	}
+	cmap, ok := tables["cmap"]
+	if !ok {
+		return nil, fmt.Errorf("ttf 'cmap' table is missing")
+	}
+	glyphs, err := ttfData(cmap).asciiGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	var pairs map[[2]uint16]int16
+	if gpos, ok := tables["GPOS"]; ok {
+		pairs, err = ttfData(gpos).gposPairs(glyphs)
+	} else if kern, ok := tables["kern"]; ok {
+		pairs, err = ttfData(kern).kernPairs()
+	} else {
+		err = fmt.Errorf("ttf has neither a 'GPOS' nor a 'kern' table")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scale := pixelsPerEm / unitsPerEm
+	kerning := make(drawings.KerningTable)
+	for left := byte(' '); left <= '~'; left++ {
+		for right := byte(' '); right <= '~'; right++ {
+			if value, ok := pairs[[2]uint16{glyphs[left], glyphs[right]}]; ok {
+				kerning[drawings.KerningPair{Left: left, Right: right}] = float64(value) * scale
+			}
+		}
+	}
+	return kerning, nil
+}
+
+type ttfData []byte
+
+func (d ttfData) u16(offset int) uint16 {
+	if offset < 0 || offset+2 > len(d) {
+		return 0
+	}
+	return uint16(d[offset])<<8 | uint16(d[offset+1])
+}
+
+func (d ttfData) u32(offset int) uint32 {
+	if offset < 0 || offset+4 > len(d) {
+		return 0
+	}
+	return uint32(d.u16(offset))<<16 | uint32(d.u16(offset+2))
+}
+
+// slice returns length bytes at offset, or nil when they are outside the data.
+func (d ttfData) slice(offset, length int) []byte {
+	if offset < 0 || offset+length > len(d) {
+		return nil
+	}
+	return d[offset : offset+length]
+}
+
+func (d ttfData) tables() (map[string][]byte, error) {
+	if len(d) < 12 {
+		return nil, fmt.Errorf("ttf data is too short")
+	}
+	numTables := int(d.u16(4))
+	tables := make(map[string][]byte)
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if record+16 > len(d) {
+			return nil, fmt.Errorf("ttf table directory is truncated")
+		}
+		tag := string(d[record : record+4])
+		offset := int(d.u32(record + 8))
+		length := int(d.u32(record + 12))
+		if offset+length > len(d) {
+			return nil, fmt.Errorf("ttf table %q is truncated", tag)
+		}
+		tables[tag] = d[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// asciiGlyphs maps the printable ASCII characters to glyph indices using a format 4 unicode subtable.
+func (d ttfData) asciiGlyphs() (map[byte]uint16, error) {
+	numTables := int(d.u16(2))
+	subtable := -1
+	for i := 0; i < numTables; i++ {
+		record := 4 + i*8
+		platform := d.u16(record)
+		encoding := d.u16(record + 2)
+		offset := int(d.u32(record + 4))
+		if (platform == 0 || (platform == 3 && encoding == 1)) && d.u16(offset) == 4 {
+			subtable = offset
+			break
+		}
+	}
+	if subtable < 0 {
+		return nil, fmt.Errorf("ttf has no format 4 unicode cmap")
+	}
+
+	segCount := int(d.u16(subtable+6)) / 2
+	endCodes := subtable + 14
+	startCodes := endCodes + segCount*2 + 2
+	idDeltas := startCodes + segCount*2
+	idRangeOffsets := idDeltas + segCount*2
+
+	glyphs := make(map[byte]uint16)
+	for char := uint16(' '); char <= '~'; char++ {
+		for seg := 0; seg < segCount; seg++ {
+			if d.u16(endCodes+seg*2) < char {
+				continue
+			}
+			start := d.u16(startCodes + seg*2)
+			if start > char {
+				break
+			}
+			delta := d.u16(idDeltas + seg*2)
+			rangeOffset := int(d.u16(idRangeOffsets + seg*2))
+			if rangeOffset == 0 {
+				glyphs[byte(char)] = char + delta
+			} else {
+				glyph := d.u16(idRangeOffsets + seg*2 + rangeOffset + int(char-start)*2)
+				if glyph != 0 {
+					glyph += delta
+				}
+				glyphs[byte(char)] = glyph
+			}
+			break
+		}
+	}
+	return glyphs, nil
+}
+
+func (d ttfData) kernPairs() (map[[2]uint16]int16, error) {
+	if d.u16(0) != 0 {
+		return nil, fmt.Errorf("ttf kern table version %d is not supported", d.u16(0))
+	}
+	pairs := make(map[[2]uint16]int16)
+	numTables := int(d.u16(2))
+	offset := 4
+	for i := 0; i < numTables; i++ {
+		length := int(d.u16(offset + 2))
+		coverage := d.u16(offset + 4)
+		horizontal := coverage&0x01 != 0
+		minimum := coverage&0x02 != 0
+		crossStream := coverage&0x04 != 0
+		if coverage>>8 == 0 && horizontal && !minimum && !crossStream {
+			nPairs := int(d.u16(offset + 6))
+			for p := 0; p < nPairs; p++ {
+				pair := offset + 14 + p*6
+				pairs[[2]uint16{d.u16(pair), d.u16(pair + 2)}] = int16(d.u16(pair + 4))
+			}
+		}
+		if length == 0 {
+			break
+		}
+		offset += length
+	}
+	return pairs, nil
+}
+
+// gposPairs returns the x advance adjustments of the first glyph of the pairs of the glyphs
+// in the pair adjustment lookups (type 2, also inside extension lookups) of the 'kern' feature.
+func (d ttfData) gposPairs(glyphs map[byte]uint16) (map[[2]uint16]int16, error) {
+	if d.u16(0) != 1 {
+		return nil, fmt.Errorf("ttf GPOS table version %d is not supported", d.u16(0))
+	}
+	features := int(d.u16(6))
+	lookupList := int(d.u16(8))
+	var lookups []int
+	for i := 0; i < int(d.u16(features)); i++ {
+		record := features + 2 + i*6
+		if string(d.slice(record, 4)) != "kern" {
+			continue
+		}
+		feature := features + int(d.u16(record+4))
+		for l := 0; l < int(d.u16(feature+2)); l++ {
+			lookups = append(lookups, int(d.u16(feature+4+l*2)))
+		}
+	}
+	if len(lookups) == 0 {
+		return nil, fmt.Errorf("ttf GPOS table has no 'kern' feature")
+	}
+
+	pairs := make(map[[2]uint16]int16)
+	for _, index := range lookups {
+		if index >= int(d.u16(lookupList)) {
+			return nil, fmt.Errorf("ttf GPOS lookup %d is missing", index)
+		}
+		lookup := lookupList + int(d.u16(lookupList+2+index*2))
+		lookupType := d.u16(lookup)
+		// within a lookup the first subtable covering a pair applies, lookups add up
+		adjustments := make(map[[2]uint16]int16)
+		for s := 0; s < int(d.u16(lookup+4)); s++ {
+			subtable := lookup + int(d.u16(lookup+6+s*2))
+			if lookupType == 9 {
+				if d.u16(subtable+2) != 2 {
+					continue
+				}
+				subtable += int(d.u32(subtable + 4))
+			} else if lookupType != 2 {
+				continue
+			}
+			for _, left := range glyphs {
+				for _, right := range glyphs {
+					pair := [2]uint16{left, right}
+					if _, ok := adjustments[pair]; ok {
+						continue
+					}
+					if value, ok := d.pairAdjustment(subtable, left, right); ok {
+						adjustments[pair] = value
+					}
+				}
+			}
+		}
+		for pair, value := range adjustments {
+			if value != 0 {
+				pairs[pair] += value
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// pairAdjustment returns the x advance adjustment of the left glyph in a format 1 or 2 pair
+// adjustment subtable, false when the subtable does not cover the pair.
+func (d ttfData) pairAdjustment(subtable int, left, right uint16) (int16, bool) {
+	coverage := d.coverageIndex(subtable+int(d.u16(subtable+2)), left)
+	if coverage < 0 {
+		return 0, false
+	}
+	format1 := d.u16(subtable + 4)
+	format2 := d.u16(subtable + 6)
+	size := valueRecordSize(format1) + valueRecordSize(format2)
+	switch d.u16(subtable) {
+	case 1:
+		if coverage >= int(d.u16(subtable+8)) {
+			return 0, false
+		}
+		set := subtable + int(d.u16(subtable+10+coverage*2))
+		for i := 0; i < int(d.u16(set)); i++ {
+			record := set + 2 + i*(2+size)
+			if d.u16(record) == right {
+				return d.xAdvance(record+2, format1), true
+			}
+		}
+	case 2:
+		class1 := d.glyphClass(subtable+int(d.u16(subtable+8)), left)
+		class2 := d.glyphClass(subtable+int(d.u16(subtable+10)), right)
+		class1Count := int(d.u16(subtable + 12))
+		class2Count := int(d.u16(subtable + 14))
+		if class1 >= class1Count || class2 >= class2Count {
+			return 0, false
+		}
+		record := subtable + 16 + (class1*class2Count+class2)*size
+		return d.xAdvance(record, format1), true
+	}
+	return 0, false
+}
+
+// valueRecordSize returns the size in bytes of a GPOS value record of the format.
+func valueRecordSize(format uint16) int {
+	size := 0
+	for ; format != 0; format >>= 1 {
+		size += 2 * int(format&1)
+	}
+	return size
+}
+
+// xAdvance returns the x advance of the value record, it follows the x and y placements.
+func (d ttfData) xAdvance(record int, format uint16) int16 {
+	if format&0x0004 == 0 {
+		return 0
+	}
+	return int16(d.u16(record + valueRecordSize(format&0x0003)))
+}
+
+// coverageIndex returns the index of the glyph in a coverage table or -1.
+func (d ttfData) coverageIndex(coverage int, glyph uint16) int {
+	switch d.u16(coverage) {
+	case 1:
+		for i := 0; i < int(d.u16(coverage+2)); i++ {
+			if d.u16(coverage+4+i*2) == glyph {
+				return i
+			}
+		}
+	case 2:
+		for i := 0; i < int(d.u16(coverage+2)); i++ {
+			record := coverage + 4 + i*6
+			start := d.u16(record)
+			if glyph >= start && glyph <= d.u16(record+2) {
+				return int(d.u16(record+4)) + int(glyph-start)
+			}
+		}
+	}
+	return -1
+}
+
+// glyphClass returns the class of the glyph in a class definition table, 0 when it has none.
+func (d ttfData) glyphClass(classDef int, glyph uint16) int {
+	switch d.u16(classDef) {
+	case 1:
+		start := d.u16(classDef + 2)
+		if glyph >= start && int(glyph-start) < int(d.u16(classDef+4)) {
+			return int(d.u16(classDef + 6 + int(glyph-start)*2))
+		}
+	case 2:
+		for i := 0; i < int(d.u16(classDef+2)); i++ {
+			record := classDef + 4 + i*6
+			if glyph >= d.u16(record) && glyph <= d.u16(record+2) {
+				return int(d.u16(record + 4))
+			}
+		}
+	}
+	return 0
+}
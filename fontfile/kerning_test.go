@@ -0,0 +1,189 @@
+package fontfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// u16s encodes big-endian 16 bit values, negative values as int16.
+func u16s(values ...int) []byte {
+	b := make([]byte, 0, 2*len(values))
+	for _, v := range values {
+		b = append(b, byte(uint16(v)>>8), byte(v))
+	}
+	return b
+}
+
+func u32(v int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// glyphOf is the glyph index testTTF maps the character to.
+func glyphOf(c byte) int {
+	return int(c) - 29
+}
+
+// testTTF builds a font with a head and a format 4 cmap for the printable ASCII characters
+// and the extra tables.
+func testTTF(extra map[string][]byte) []byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	cmap := join(
+		u16s(0, 1, 3, 1), []byte{0, 0, 0, 12},
+		u16s(4, 32, 0, 4, 4, 1, 0),
+		u16s('~', 0xFFFF, 0),
+		u16s(' ', 0xFFFF),
+		u16s(-29, 1),
+		u16s(0, 0),
+	)
+	tables := map[string][]byte{"head": head, "cmap": cmap}
+	for tag, table := range extra {
+		tables[tag] = table
+	}
+	tags := []string{}
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	directory := u16s(1, 0, len(tags), 0, 0, 0)
+	var data []byte
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		directory = append(directory, tag...)
+		directory = append(directory, u32(0)...)
+		directory = append(directory, u32(offset+len(data))...)
+		directory = append(directory, u32(len(tables[tag]))...)
+		data = append(data, tables[tag]...)
+	}
+	return join(directory, data)
+}
+
+// testGPOS builds a GPOS table with a 'kern' feature of one lookup of the type and subtable.
+func testGPOS(lookupType int, subtable []byte) []byte {
+	return join(
+		u16s(1, 0, 0, 10, 24),
+		u16s(1), []byte("kern"), u16s(8),
+		u16s(0, 1, 0),
+		u16s(1, 4),
+		u16s(lookupType, 0, 1, 8),
+		subtable,
+	)
+}
+
+// pairPosFormat1 kerns A V by -80 and A o by -40 units.
+var pairPosFormat1 = join(
+	u16s(1, 12, 0x0004, 0, 1, 18),
+	u16s(1, 1, glyphOf('A')),
+	u16s(2, glyphOf('V'), -80, glyphOf('o'), -40),
+)
+
+// pairPosFormat2 kerns the classes of A and T before o, the value records have an x placement.
+var pairPosFormat2 = join(
+	u16s(2, 32, 0x0005, 0, 48, 56, 2, 2),
+	u16s(0, 0, 7, -10),
+	u16s(0, 0, 0, -60),
+	u16s(2, 2, glyphOf('A'), glyphOf('A'), 0, glyphOf('T'), glyphOf('T'), 1),
+	u16s(1, glyphOf('T'), 1, 1),
+	u16s(2, 1, glyphOf('o'), glyphOf('o'), 1),
+)
+
+func kernTable(pairs ...int) []byte {
+	n := len(pairs) / 3
+	return join(u16s(0, 1, 0, 14+6*n, 0x0001, n, 0, 0, 0), u16s(pairs...))
+}
+
+func checkKerning(t *testing.T, got drawings.KerningTable, want map[string]float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d pairs %v, want %d", len(got), got, len(want))
+	}
+	for pair, value := range want {
+		k := got[drawings.KerningPair{Left: pair[0], Right: pair[1]}]
+		if math.Abs(k-value) > 1e-9 {
+			t.Errorf("kerning %q = %v, want %v", pair, k, value)
+		}
+	}
+}
+
+func TestLoadTTFKerning(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables map[string][]byte
+		want   map[string]float64
+	}{
+		{
+			name:   "kern",
+			tables: map[string][]byte{"kern": kernTable(glyphOf('A'), glyphOf('V'), -100, glyphOf('T'), glyphOf('o'), 50)},
+			want:   map[string]float64{"AV": -1, "To": 0.5},
+		},
+		{
+			name:   "gpos pair format 1",
+			tables: map[string][]byte{"GPOS": testGPOS(2, pairPosFormat1)},
+			want:   map[string]float64{"AV": -0.8, "Ao": -0.4},
+		},
+		{
+			name:   "gpos pair format 2",
+			tables: map[string][]byte{"GPOS": testGPOS(2, pairPosFormat2)},
+			want:   map[string]float64{"Ao": -0.1, "To": -0.6},
+		},
+		{
+			name:   "gpos extension",
+			tables: map[string][]byte{"GPOS": testGPOS(9, join(u16s(1, 2, 0, 8), pairPosFormat1))},
+			want:   map[string]float64{"AV": -0.8, "Ao": -0.4},
+		},
+		{
+			name: "gpos before kern",
+			tables: map[string][]byte{
+				"GPOS": testGPOS(2, pairPosFormat1),
+				"kern": kernTable(glyphOf('A'), glyphOf('V'), -100),
+			},
+			want: map[string]float64{"AV": -0.8, "Ao": -0.4},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kerning, err := LoadTTFKerning(bytes.NewReader(testTTF(test.tables)), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkKerning(t, kerning, test.want)
+		})
+	}
+}
+
+func TestLoadTTFKerningErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"no kerning tables": testTTF(nil),
+		"no kern feature":   testTTF(map[string][]byte{"GPOS": u16s(1, 0, 0, 10, 12, 0, 0)}),
+		"truncated":         testTTF(nil)[:20],
+	}
+	for name, data := range tests {
+		if _, err := LoadTTFKerning(bytes.NewReader(data), 10); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadKerning(t *testing.T) {
+	kerning, err := LoadKerning(strings.NewReader("# left right adjustment\nA V -2\n\n0x20 T -1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkKerning(t, kerning, map[string]float64{"AV": -2, " T": -1.5})
+
+	for _, text := range []string{"A V", "AB V -1", "0xZZ V -1", "A V x"} {
+		if _, err := LoadKerning(strings.NewReader(text)); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
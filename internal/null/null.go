@@ -0,0 +1,35 @@
+// Package null is a pixel device that draws nothing, for sketchers that only measure text
+// and keep the drawing state, and for benchmarks of the sketcher itself.
+package null
+
+type device struct {
+	width  int
+	height int
+}
+
+func NewNullDevice(width, height int) *device {
+	return &device{
+		width:  width,
+		height: height,
+	}
+}
+
+func (dev *device) Pixel(x, y int, color any) error {
+	return nil
+}
+
+func (dev *device) Clear(color any) error {
+	return nil
+}
+
+func (dev *device) Update() int {
+	return 0
+}
+
+func (dev *device) ScreenWidth() int {
+	return dev.width
+}
+
+func (dev *device) ScreenHeight() int {
+	return dev.height
+}
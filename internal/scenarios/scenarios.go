@@ -0,0 +1,429 @@
+// Package scenarios holds the drawings of the manual tests, shared by test-sketcher on the
+// LCD and sketch-sim in the browser.
+package scenarios
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/svg"
+	"github.com/marksaravi/drivers-go/colors"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+type Scenario struct {
+	Name string
+	Draw func(sketcher drawings.Sketcher)
+}
+
+// All are the scenarios in the order they are shown.
+var All = []Scenario{
+	{"points", drawPoints},
+	{"cross-lines", drawCrossLines},
+	{"lines", drawLines},
+	{"arc", drawArc},
+	{"thick-arc", draThickwArc},
+	{"directed-arc", drawDirectedArc},
+	{"circle", drawCircle},
+	{"fill-circle", drawFillCircle},
+	{"thick-circle", drawThickCircle},
+	{"rectangle", drawRectangle},
+	{"fill-rectangle", drawFillRectangle},
+	{"thick-rectangle", drawThickRectangle},
+	{"curves", drawCurves},
+	{"paths", drawPaths},
+	{"gradients", drawGradients},
+	{"svg", drawSVG},
+	{"fonts-area", drawFontsArea},
+	{"digits", drawDigits},
+	{"rotated-text", drawRotatedText},
+	{"scaled-text", drawScaledText},
+	{"calibration-points", drawCalibrationPoints},
+}
+
+// Find returns the scenario with the name.
+func Find(name string) (Scenario, bool) {
+	for _, s := range All {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Scenario{}, false
+}
+
+func drawPoints(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	sketcher.Circle(0, 0, 5, colors.RED)
+}
+
+func drawCrossLines(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	xmax := float64(sketcher.ScreenWidth() - 1)
+	ymax := float64(sketcher.ScreenHeight() - 1)
+	sketcher.Clear(colors.BLACK)
+	const OFFSET = 5
+	sketcher.Line(OFFSET, OFFSET, xmax-OFFSET, OFFSET, colors.RED)
+	sketcher.Line(xmax-OFFSET, OFFSET, xmax-OFFSET, ymax-OFFSET, colors.GREEN)
+	sketcher.Line(xmax-OFFSET, ymax-OFFSET, OFFSET, ymax-OFFSET, colors.BLUE)
+	sketcher.Line(OFFSET, ymax-OFFSET, OFFSET, OFFSET, colors.PINK)
+}
+
+func drawLines(sketcher drawings.Sketcher) {
+	xmax := float64(sketcher.ScreenWidth() - 1)
+	ymax := float64(sketcher.ScreenHeight() - 1)
+	xc := xmax / 2
+	yc := ymax / 2
+	radius := ymax / 2
+	sAngle := math.Pi / 180 * 0
+	rAngle := 2 * math.Pi
+	dAngle := math.Pi / 180 * 5
+
+	sketcher.Clear(colors.WHITE)
+	for angle := sAngle; angle < sAngle+rAngle; angle += dAngle {
+		x := math.Cos(angle) * radius
+		y := math.Sin(angle) * radius
+		sketcher.Line(xc, yc, xc+x, yc+y, colors.BLUE)
+	}
+}
+
+func drawCircle(sketcher drawings.Sketcher) {
+	const N int = 3
+	xmax := float64(sketcher.ScreenWidth() - 1)
+	ymax := float64(sketcher.ScreenHeight() - 1)
+	xc := xmax / 2
+	yc := ymax / 2
+	radius := ymax / 2.1
+	xyc := [N][]float64{{xc, yc, radius}, {xc, yc, radius * .75}, {xc, yc, radius * .45}}
+	colorset := [N]colors.Color{colors.BLACK, colors.DARKBLUE, colors.DARKGREEN}
+	for i := 0; i < N; i++ {
+		sketcher.Circle(xyc[i][0], xyc[i][1], xyc[i][2], colorset[i])
+	}
+}
+
+func drawFillCircle(sketcher drawings.Sketcher) {
+	const N int = 3
+	xyc := [N][]float64{{30, 30, 45}, {160, 120, 115}, {400, 400, 250}}
+	colorset := [N]colors.Color{colors.BLACK, colors.DARKBLUE, colors.DARKGREEN}
+	for i := 0; i < N; i++ {
+		sketcher.FillCircle(xyc[i][0], xyc[i][1], xyc[i][2], colorset[i])
+	}
+}
+
+func drawThickCircle(sketcher drawings.Sketcher) {
+	const N int = 3
+	xmax := float64(sketcher.ScreenWidth() - 1)
+	ymax := float64(sketcher.ScreenHeight() - 1)
+	xc := xmax / 2
+	yc := ymax / 2
+	radius := ymax / 2.1
+	xyc := [N][]float64{{xc, yc, radius}, {xc, yc, radius * .75}, {xc, yc, radius * .45}}
+	colorset := [N]colors.Color{colors.ROYALBLUE, colors.SILVER, colors.MEDIUMSPRINGGREEN}
+	widhTypes := [N]drawings.WidthType{drawings.INNER_WIDTH, drawings.CENTER_WIDTH, drawings.OUTER_WIDTH}
+	const width = 10
+	for i := 0; i < N; i++ {
+		sketcher.ThickCircle(xyc[i][0], xyc[i][1], xyc[i][2], width, widhTypes[i], colorset[i])
+		sketcher.Circle(xyc[i][0], xyc[i][1], xyc[i][2], colors.RED)
+	}
+}
+
+func drawArc(sketcher drawings.Sketcher) {
+	const N int = 12
+	colorset := [N]colors.Color{
+		colors.RED,
+		colors.GREEN,
+		colors.BLUE,
+		colors.BLACK,
+		colors.RED,
+		colors.GREEN,
+		colors.BLUE,
+		colors.BLACK,
+		colors.RED,
+		colors.GREEN,
+		colors.BLUE,
+		colors.BLACK,
+	}
+
+	xyc := [N][]float64{
+		{160, 120, 50, drawings.DegToRad(0), drawings.DegToRad(90)},
+		{160, 120, 55, drawings.DegToRad(90), drawings.DegToRad(180)},
+		{160, 120, 60, drawings.DegToRad(180), drawings.DegToRad(270)},
+		{160, 120, 65, drawings.DegToRad(270), drawings.DegToRad(360)},
+		{160, 120, 70, drawings.DegToRad(15), drawings.DegToRad(45)},
+		{160, 120, 75, drawings.DegToRad(45), drawings.DegToRad(15)},
+		{160, 120, 80, drawings.DegToRad(105), drawings.DegToRad(135)},
+		{160, 120, 85, drawings.DegToRad(135), drawings.DegToRad(105)},
+		{160, 120, 90, drawings.DegToRad(195), drawings.DegToRad(225)},
+		{160, 120, 95, drawings.DegToRad(225), drawings.DegToRad(195)},
+		{160, 120, 100, drawings.DegToRad(285), drawings.DegToRad(315)},
+		{160, 120, 105, drawings.DegToRad(315), drawings.DegToRad(285)},
+	}
+	for i := 0; i < N; i++ {
+		sketcher.Arc(xyc[i][0], xyc[i][1], xyc[i][2], xyc[i][3], xyc[i][4], colorset[i])
+	}
+	sketcher.Line(160, 0, 160, 239, colors.RED)
+	sketcher.Line(0, 120, 319, 120, colors.RED)
+}
+
+func draThickwArc(sketcher drawings.Sketcher) {
+	const N int = 3
+	colorset := [N]colors.Color{
+		colors.CYAN,
+		colors.GREEN,
+		colors.LIGHTBLUE,
+	}
+
+	widhTypes := [N]drawings.WidthType{drawings.OUTER_WIDTH, drawings.CENTER_WIDTH, drawings.INNER_WIDTH}
+	xyc := [N][]float64{
+		{160, 120, 70, drawings.DegToRad(45), drawings.DegToRad(175)},
+		{160, 120, 90, drawings.DegToRad(15), drawings.DegToRad(300)},
+		{160, 120, 115, drawings.DegToRad(300), drawings.DegToRad(15)},
+	}
+
+	for i := 0; i < N; i++ {
+		sketcher.ThickArc(xyc[i][0], xyc[i][1], xyc[i][2], xyc[i][3], xyc[i][4], 10, widhTypes[i], colorset[i])
+		sketcher.Arc(xyc[i][0], xyc[i][1], xyc[i][2], xyc[i][3], xyc[i][4], colors.RED)
+	}
+}
+
+func drawDirectedArc(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	sketcher.DirectedArc(160, 120, 60, drawings.DegToRad(-45), drawings.DegToRad(45), drawings.CLOCKWISE, colors.RED)
+	sketcher.DirectedArc(160, 120, 70, drawings.DegToRad(-45), drawings.DegToRad(45), drawings.COUNTER_CLOCKWISE, colors.BLUE)
+	sketcher.Arc(160, 120, 80, drawings.DegToRad(-720), drawings.DegToRad(0), colors.GREEN)
+}
+
+func drawRectangle(sketcher drawings.Sketcher) {
+	const N int = 2
+	xy := [N][]float64{{10, 10, 100, 100}, {50, 50, 200, 200}}
+	colorset := [N]colors.Color{colors.BLUE, colors.GREEN}
+	for i := 0; i < 2; i++ {
+		sketcher.Rectangle(xy[i][0], xy[i][1], xy[i][2], xy[i][3], colorset[i])
+	}
+
+}
+
+func drawFillRectangle(sketcher drawings.Sketcher) {
+	const N int = 2
+	xy := [N][]float64{{100, 100, 10, 10}, {50, 50, 200, 200}}
+	colors := [N]colors.Color{colors.BLUE, colors.GREEN}
+	for i := 0; i < 2; i++ {
+		sketcher.FillRectangle(xy[i][0], xy[i][1], xy[i][2], xy[i][3], colors[i])
+	}
+
+}
+
+func drawThickRectangle(sketcher drawings.Sketcher) {
+	const N int = 3
+	xy := [N][]float64{{100, 100, 10, 10}, {50, 50, 200, 200}, {100, 100, 300, 220}}
+	colorset := [N]colors.Color{colors.ROYALBLUE, colors.NAVY, colors.FORESTGREEN}
+	widhTypes := [N]drawings.WidthType{drawings.INNER_WIDTH, drawings.CENTER_WIDTH, drawings.OUTER_WIDTH}
+	const width = 10
+	for i := 0; i < N; i++ {
+		sketcher.ThickRectangle(xy[i][0], xy[i][1], xy[i][2], xy[i][3], width, widhTypes[i], colorset[i])
+		sketcher.Rectangle(xy[i][0], xy[i][1], xy[i][2], xy[i][3], colors.RED)
+	}
+
+}
+
+func drawCurves(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	points := []drawings.Point{{X: 10, Y: 200}, {X: 60, Y: 80}, {X: 120, Y: 150}, {X: 180, Y: 40}, {X: 240, Y: 120}, {X: 310, Y: 60}}
+	sketcher.ThickSpline(points, 5, colors.LIGHTBLUE)
+	sketcher.Spline(points, colors.BLUE)
+	for _, p := range points {
+		sketcher.FillCircle(p.X, p.Y, 3, colors.RED)
+	}
+	sketcher.QuadBezier(10, 230, 160, 120, 310, 230, colors.GREEN)
+	sketcher.ThickCubicBezier(10, 10, 100, 100, 220, -60, 310, 30, 3, colors.DARKGREEN)
+	sketcher.FillSpline([]drawings.Point{{X: 140, Y: 170}, {X: 180, Y: 200}, {X: 140, Y: 230}, {X: 100, Y: 200}}, colors.ORANGE)
+}
+
+func drawPaths(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	bubble := drawings.NewPath().
+		MoveTo(30, 20).
+		LineTo(290, 20).
+		ArcTo(10, 10, 0, false, true, 300, 30).
+		LineTo(300, 130).
+		ArcTo(10, 10, 0, false, true, 290, 140).
+		LineTo(90, 140).
+		LineTo(60, 180).
+		LineTo(70, 140).
+		LineTo(30, 140).
+		ArcTo(10, 10, 0, false, true, 20, 130).
+		LineTo(20, 30).
+		ArcTo(10, 10, 0, false, true, 30, 20).
+		Close()
+	sketcher.FillPath(bubble, drawings.NON_ZERO, colors.LIGHTBLUE)
+	sketcher.StrokePath(bubble, 3, colors.NAVY)
+
+	ring := drawings.NewPath().
+		MoveTo(200, 200).ArcTo(30, 30, 0, true, true, 200, 199.9).Close().
+		MoveTo(215, 200).ArcTo(15, 15, 0, true, true, 215, 199.9).Close()
+	sketcher.FillPath(ring.Transform(1, 0, 0, 1, 40, 0), drawings.EVEN_ODD, colors.ORANGE)
+}
+
+func drawGradients(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	sketcher.FillRectangleGradient(10, 10, 150, 50, colors.NAVY, colors.LIGHTBLUE, false)
+	sketcher.FillRectangleGradient(170, 10, 310, 50, drawings.RGB(0x20, 0x20, 0x20), drawings.RGB(0x50, 0x50, 0x50), true)
+
+	sunset := drawings.LinearGradient{
+		X1: 0, Y1: 60, X2: 0, Y2: 140,
+		Stops: []drawings.GradientStop{
+			{Offset: 0, Color: drawings.RGB(0x1A, 0x23, 0x7E)},
+			{Offset: 0.6, Color: drawings.RGB(0xF4, 0x51, 0x1E)},
+			{Offset: 1, Color: drawings.RGB(0xFF, 0xD5, 0x4F)},
+		},
+		Dither: true,
+	}
+	sketcher.FillRectangle(10, 60, 150, 140, sunset)
+	sketcher.FillPolygon([]drawings.Point{{X: 170, Y: 140}, {X: 240, Y: 60}, {X: 310, Y: 140}}, sunset)
+
+	glow := drawings.RadialGradient{
+		X: 80, Y: 190, Radius: 40,
+		Stops: []drawings.GradientStop{
+			{Offset: 0, Color: drawings.RGB(0xFF, 0xFF, 0xFF)},
+			{Offset: 1, Color: drawings.RGB(0x00, 0x96, 0x88)},
+		},
+		Dither: true,
+	}
+	sketcher.FillCircle(80, 190, 40, glow)
+
+	sketcher.SetFont(fonts.FreeSansBold18pt7b)
+	sketcher.MoveCursor(140, 205)
+	sketcher.Write("Paint", drawings.LinearGradient{
+		X1: 140, Y1: 0, X2: 240, Y2: 0,
+		Stops: []drawings.GradientStop{
+			{Offset: 0, Color: drawings.RGB(0xE5, 0x39, 0x35)},
+			{Offset: 1, Color: drawings.RGB(0x3F, 0x51, 0xB5)},
+		},
+	})
+}
+
+const testIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24">
+	<circle cx="12" cy="12" r="10" fill="#FFD54F" stroke="#F57F17" stroke-width="1"/>
+	<ellipse cx="8.5" cy="9.5" rx="1.5" ry="2"/>
+	<ellipse cx="15.5" cy="9.5" rx="1.5" ry="2"/>
+	<path d="M7 14a5 5 0 0 0 10 0" fill="none" stroke="black" stroke-width="1.5"/>
+	<g transform="rotate(-20 20 4)"><rect x="17" y="1" width="6" height="6" rx="1" fill="#E53935"/></g>
+</svg>`
+
+func drawSVG(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	img, err := svg.Parse(strings.NewReader(testIcon))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	img.Draw(sketcher, 10, 10, 200, 200, nil)
+	img.Draw(sketcher, 220, 10, 48, 48, nil)
+	img.Draw(sketcher, 220, 70, 24, 24, nil)
+}
+
+func drawFontsArea(sketcher drawings.Sketcher) {
+	sketcher.SetFont(fonts.FreeSerif18pt7b)
+	const LEN = 12
+	const FROM byte = 0x20 + 20
+	const TO byte = 0x7E
+	var c byte = FROM
+	yline := float64(32)
+
+	for c <= TO {
+		s := make([]byte, 0)
+		for i := 0; i < LEN && c <= TO; i++ {
+			s = append(s, c)
+			c++
+		}
+		text := string(s)
+		xoffset := float64(8)
+		x1, y1, x2, y2 := sketcher.GetTextArea(float64(xoffset), float64(yline), text, 1, 1)
+
+		sketcher.Rectangle(float64(x1), float64(y1), float64(x2), float64(y2), colors.RED)
+		sketcher.Line(0, float64(yline), 319, float64(yline), colors.BLUE)
+		sketcher.MoveCursor(xoffset, yline)
+		sketcher.Write(string(s), colors.BLACK)
+		yline += 48
+	}
+}
+
+func drawGrids(sketcher drawings.Sketcher) {
+	for x := float64(0); x < 320; x += 32 {
+		sketcher.Line(x, 0, x, 239, colors.RED)
+	}
+	for y := float64(0); y < 240; y += 24 {
+		sketcher.Line(0, y, 319, y, colors.RED)
+	}
+}
+
+func drawDigits(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_270)
+	sketcher.SetFont(fonts.FreeSans24pt7b)
+	X := float64(30)
+	Y := float64(120)
+	xScale := float64(1)
+	yScale := float64(1)
+	value := 23.2
+	text := fmt.Sprintf("%4.1f", value)
+	x1, y1, x2, y2 := sketcher.GetTextArea(float64(X), float64(Y), text, xScale, yScale)
+	sketcher.Rectangle(float64(x1), float64(y1), float64(x2), float64(y2), colors.RED)
+	sketcher.MoveCursor(X, Y)
+	sketcher.Write(text, colors.BLACK)
+}
+
+func drawRotatedText(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	sketcher.SetFont(fonts.FreeSans9pt7b)
+	angles := []float64{0, drawings.DEG90, drawings.DEG180, drawings.DEG270, drawings.DegToRad(30)}
+	const X float64 = 160
+	const Y float64 = 120
+	for _, angle := range angles {
+		x1, y1, x2, y2 := sketcher.GetRotatedTextArea(X, Y, "Axis label", 1, 1, angle)
+		sketcher.Rectangle(x1, y1, x2, y2, colors.RED)
+		sketcher.MoveCursor(X, Y)
+		sketcher.WriteRotated("Axis label", angle, colors.BLACK)
+	}
+}
+
+func drawScaledText(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_0)
+	sketcher.SetFont(fonts.FreeSans9pt7b)
+	sketcher.SetFontSmoothing(true, colors.WHITE)
+	const X1, Y1, X2, Y2 float64 = 10, 20, 310, 100
+	sketcher.Rectangle(X1, Y1, X2, Y2, colors.RED)
+	text := "Heading"
+	scale := sketcher.GetTextScaleToFit(text, X2-X1, Y2-Y1)
+	_, y1, _, _ := sketcher.GetTextArea(0, 0, text, scale, scale)
+	sketcher.MoveCursor(X1, Y1-y1)
+	sketcher.WriteScaled(text, scale, scale, colors.BLACK)
+	sketcher.MoveCursor(X1, 160)
+	sketcher.WriteScaled("1.5x scaled", 1.5, 1.5, colors.BLUE)
+	sketcher.SetFontSmoothing(false, nil)
+}
+
+func drawCalibrationPoints(sketcher drawings.Sketcher) {
+	sketcher.SetRotation(drawings.ROTATION_180)
+	const PADDING float64 = 40
+	const RADIUS float64 = 5
+	const N_SEGMENTS int = 2
+	var X_OFFSET float64 = (float64(sketcher.ScreenWidth()) - PADDING*2) / float64(N_SEGMENTS)
+	var Y_OFFSET float64 = (float64(sketcher.ScreenHeight()) - PADDING*2) / float64(N_SEGMENTS)
+
+	for xseg := 0; xseg <= N_SEGMENTS; xseg++ {
+		x := float64(xseg) * X_OFFSET
+		for yseg := 0; yseg <= N_SEGMENTS; yseg++ {
+			y := float64(yseg) * Y_OFFSET
+			sketcher.FillCircle(x+PADDING, y+PADDING, RADIUS, colors.RED)
+		}
+	}
+
+	for i := float64(0); i <= 0; i++ {
+		sketcher.Line(0+i, 0+i, 319-i, 0+i, colors.RED)
+		sketcher.Line(319-i, 0+i, 319-i, 239-i, colors.RED)
+		sketcher.Line(319-i, 239-i, 0+i, 239-i, colors.RED)
+		sketcher.Line(0+i, 239-i, 0+i, 0+i, colors.RED)
+	}
+}
@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteAck answers the Update command that ended a frame, with the first error of the frame if any.
+// Acks are text lines, "ack <frame>" or "error <frame> <message>", for both formats.
+func WriteAck(w io.Writer, frame int, err error) error {
+	line := fmt.Sprintf("ack %d\n", frame)
+	if err != nil {
+		message := strings.ReplaceAll(err.Error(), "\n", " ")
+		line = fmt.Sprintf("error %d %s\n", frame, message)
+	}
+	_, werr := io.WriteString(w, line)
+	return werr
+}
+
+// ReadAck reads the next ack and returns its frame and error.
+func ReadAck(r *bufio.Reader) (int, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("protocol: invalid ack %q", line)
+	}
+	frame, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, fmt.Errorf("protocol: invalid ack %q", line)
+	}
+	switch {
+	case fields[0] == "ack" && len(fields) == 2:
+		return frame, nil
+	case fields[0] == "error" && len(fields) == 3:
+		return frame, errors.New(fields[2])
+	}
+	return 0, fmt.Errorf("protocol: invalid ack %q", line)
+}
@@ -0,0 +1,452 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+// colour tags of the binary format
+const (
+	colorNil    byte = 0
+	colorRGB888 byte = 1
+	colorRGB565 byte = 2
+	colorInt    byte = 3
+	colorColor  byte = 4
+	colorSolid  byte = 5
+	colorLinear byte = 6
+	colorRadial byte = 7
+)
+
+// pathPoints is the number of points of each path segment op.
+var pathPoints = map[drawings.PathOp]int{
+	drawings.MOVE_TO:  1,
+	drawings.LINE_TO:  1,
+	drawings.QUAD_TO:  2,
+	drawings.CUBIC_TO: 3,
+	drawings.CLOSE:    0,
+}
+
+type binaryWriter struct {
+	buf []byte
+}
+
+func (w *binaryWriter) float(v float64) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(v)))
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *binaryWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (w *binaryWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutVarint(b[:], v)]...)
+}
+
+func (w *binaryWriter) text(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *binaryWriter) points(points []drawings.Point) {
+	w.uvarint(uint64(len(points)))
+	for _, p := range points {
+		w.float(p.X)
+		w.float(p.Y)
+	}
+}
+
+func (w *binaryWriter) color(color any) error {
+	switch c := color.(type) {
+	case nil:
+		w.buf = append(w.buf, colorNil)
+	case colors.RGB888:
+		w.buf = append(w.buf, colorRGB888, byte(c>>16), byte(c>>8), byte(c))
+	case colors.RGB565:
+		w.buf = append(w.buf, colorRGB565, byte(c), byte(c>>8))
+	case int:
+		w.buf = append(w.buf, colorInt)
+		w.varint(int64(c))
+	case drawings.Color:
+		w.buf = append(w.buf, colorColor, c.R, c.G, c.B)
+	case drawings.Solid:
+		if _, ok := c.Color.(drawings.Paint); ok {
+			return errPaintInSolid
+		}
+		w.buf = append(w.buf, colorSolid)
+		return w.color(c.Color)
+	case drawings.LinearGradient:
+		w.buf = append(w.buf, colorLinear)
+		w.float(c.X1)
+		w.float(c.Y1)
+		w.float(c.X2)
+		w.float(c.Y2)
+		w.gradient(c.Stops, c.Dither)
+	case drawings.RadialGradient:
+		w.buf = append(w.buf, colorRadial)
+		w.float(c.X)
+		w.float(c.Y)
+		w.float(c.Radius)
+		w.gradient(c.Stops, c.Dither)
+	default:
+		return fmt.Errorf("%w: color %T", ErrUnsupportedType, color)
+	}
+	return nil
+}
+
+// arg appends one argument of the given kind.
+func (w *binaryWriter) arg(k kind, v any) error {
+	mismatch := fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	switch k {
+	case kindFloat:
+		f, ok := v.(float64)
+		if !ok {
+			return mismatch
+		}
+		w.float(f)
+	case kindString:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch
+		}
+		w.text(s)
+	case kindBool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch
+		}
+		if b {
+			w.buf = append(w.buf, 1)
+		} else {
+			w.buf = append(w.buf, 0)
+		}
+	case kindColor:
+		return w.color(v)
+	case kindPoints:
+		p, ok := v.([]drawings.Point)
+		if !ok {
+			return mismatch
+		}
+		w.points(p)
+	case kindPath:
+		p, ok := v.(*drawings.Path)
+		if !ok || p == nil {
+			return mismatch
+		}
+		segments := p.Segments()
+		w.uvarint(uint64(len(segments)))
+		for _, s := range segments {
+			w.buf = append(w.buf, byte(s.Op))
+			for _, point := range s.Points {
+				w.float(point.X)
+				w.float(point.Y)
+			}
+		}
+	case kindFont:
+		name, err := fontName(v)
+		if err != nil {
+			return err
+		}
+		w.text(name)
+	case kindKerning:
+		table, ok := v.(drawings.KerningTable)
+		if !ok && v != nil {
+			return mismatch
+		}
+		w.uvarint(uint64(len(table)))
+		for pair, offset := range table {
+			w.buf = append(w.buf, pair.Left, pair.Right)
+			w.float(offset)
+		}
+	default:
+		n, ok := enumValue(k, v)
+		if !ok {
+			return mismatch
+		}
+		w.varint(int64(n))
+	}
+	return nil
+}
+
+// gradient writes the dithering flag and the stops as offset and RGB bytes.
+func (w *binaryWriter) gradient(stops []drawings.GradientStop, dither bool) {
+	if dither {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+	w.uvarint(uint64(len(stops)))
+	for _, s := range stops {
+		w.float(s.Offset)
+		w.buf = append(w.buf, s.Color.R, s.Color.G, s.Color.B)
+	}
+}
+
+// enumValue returns the value of the drawings enum types.
+func enumValue(k kind, v any) (int, bool) {
+	switch k {
+	case kindWidthType:
+		e, ok := v.(drawings.WidthType)
+		return int(e), ok
+	case kindDirection:
+		e, ok := v.(drawings.ArcDirection)
+		return int(e), ok
+	case kindFillRule:
+		e, ok := v.(drawings.FillRule)
+		return int(e), ok
+	case kindSampling:
+		e, ok := v.(drawings.FontSampling)
+		return int(e), ok
+	}
+	return 0, false
+}
+
+// enumArg converts an enum value back to its drawings type.
+func enumArg(k kind, n int) any {
+	switch k {
+	case kindWidthType:
+		return drawings.WidthType(n)
+	case kindDirection:
+		return drawings.ArcDirection(n)
+	case kindFillRule:
+		return drawings.FillRule(n)
+	}
+	return drawings.FontSampling(n)
+}
+
+type binaryReader struct {
+	r *bufio.Reader
+}
+
+func (r *binaryReader) float() (float64, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r.r, b[:]); err != nil {
+		return 0, err
+	}
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(b[:]))), nil
+}
+
+func (r *binaryReader) text() (string, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return "", err
+	}
+	if n > math.MaxUint16 {
+		return "", fmt.Errorf("protocol: string of %d bytes is too long", n)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r.r, b)
+	return string(b), err
+}
+
+// count reads a length, limited to keep a broken stream from allocating huge buffers.
+func (r *binaryReader) count() (int, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, err
+	}
+	if n > 1<<20 {
+		return 0, fmt.Errorf("protocol: length %d is too large", n)
+	}
+	return int(n), nil
+}
+
+func (r *binaryReader) point() (drawings.Point, error) {
+	x, err := r.float()
+	if err != nil {
+		return drawings.Point{}, err
+	}
+	y, err := r.float()
+	return drawings.Point{X: x, Y: y}, err
+}
+
+func (r *binaryReader) color() (any, error) {
+	tag, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case colorNil:
+		return nil, nil
+	case colorRGB888:
+		var b [3]byte
+		if _, err := io.ReadFull(r.r, b[:]); err != nil {
+			return nil, err
+		}
+		return colors.RGB888(uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])), nil
+	case colorRGB565:
+		var b [2]byte
+		if _, err := io.ReadFull(r.r, b[:]); err != nil {
+			return nil, err
+		}
+		return colors.RGB565(binary.LittleEndian.Uint16(b[:])), nil
+	case colorInt:
+		n, err := binary.ReadVarint(r.r)
+		return int(n), err
+	case colorColor:
+		var b [3]byte
+		if _, err := io.ReadFull(r.r, b[:]); err != nil {
+			return nil, err
+		}
+		return drawings.RGB(b[0], b[1], b[2]), nil
+	case colorSolid:
+		// a solid holds a plain colour, so the colours are only read one level deep
+		next, err := r.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if next[0] == colorSolid || next[0] == colorLinear || next[0] == colorRadial {
+			return nil, errPaintInSolid
+		}
+		c, err := r.color()
+		return drawings.Solid{Color: c}, err
+	case colorLinear:
+		var v [4]float64
+		for i := range v {
+			if v[i], err = r.float(); err != nil {
+				return nil, err
+			}
+		}
+		g := drawings.LinearGradient{X1: v[0], Y1: v[1], X2: v[2], Y2: v[3]}
+		g.Stops, g.Dither, err = r.gradient()
+		return g, err
+	case colorRadial:
+		var v [3]float64
+		for i := range v {
+			if v[i], err = r.float(); err != nil {
+				return nil, err
+			}
+		}
+		g := drawings.RadialGradient{X: v[0], Y: v[1], Radius: v[2]}
+		g.Stops, g.Dither, err = r.gradient()
+		return g, err
+	}
+	return nil, fmt.Errorf("protocol: unknown color tag %d", tag)
+}
+
+func (r *binaryReader) gradient() ([]drawings.GradientStop, bool, error) {
+	dither, err := r.r.ReadByte()
+	if err != nil {
+		return nil, false, err
+	}
+	n, err := r.count()
+	if err != nil {
+		return nil, false, err
+	}
+	stops := make([]drawings.GradientStop, n)
+	for i := range stops {
+		if stops[i].Offset, err = r.float(); err != nil {
+			return nil, false, err
+		}
+		var b [3]byte
+		if _, err := io.ReadFull(r.r, b[:]); err != nil {
+			return nil, false, err
+		}
+		stops[i].Color = drawings.RGB(b[0], b[1], b[2])
+	}
+	return stops, dither != 0, nil
+}
+
+func (r *binaryReader) arg(k kind) (any, error) {
+	switch k {
+	case kindFloat:
+		return r.float()
+	case kindString:
+		return r.text()
+	case kindBool:
+		b, err := r.r.ReadByte()
+		return b != 0, err
+	case kindColor:
+		return r.color()
+	case kindPoints:
+		n, err := r.count()
+		if err != nil {
+			return nil, err
+		}
+		points := make([]drawings.Point, n)
+		for i := range points {
+			if points[i], err = r.point(); err != nil {
+				return nil, err
+			}
+		}
+		return points, nil
+	case kindPath:
+		n, err := r.count()
+		if err != nil {
+			return nil, err
+		}
+		path := drawings.NewPath()
+		for i := 0; i < n; i++ {
+			b, err := r.r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			segment := drawings.PathSegment{Op: drawings.PathOp(b)}
+			count, ok := pathPoints[segment.Op]
+			if !ok {
+				return nil, fmt.Errorf("protocol: unknown path op %d", b)
+			}
+			for j := 0; j < count; j++ {
+				p, err := r.point()
+				if err != nil {
+					return nil, err
+				}
+				segment.Points = append(segment.Points, p)
+			}
+			addSegment(path, segment)
+		}
+		return path, nil
+	case kindFont:
+		name, err := r.text()
+		if err != nil {
+			return nil, err
+		}
+		return fontByName(name)
+	case kindKerning:
+		n, err := r.count()
+		if err != nil || n == 0 {
+			return drawings.KerningTable(nil), err
+		}
+		table := make(drawings.KerningTable, n)
+		for i := 0; i < n; i++ {
+			var pair [2]byte
+			if _, err := io.ReadFull(r.r, pair[:]); err != nil {
+				return nil, err
+			}
+			offset, err := r.float()
+			if err != nil {
+				return nil, err
+			}
+			table[drawings.KerningPair{Left: pair[0], Right: pair[1]}] = offset
+		}
+		return table, nil
+	}
+	n, err := binary.ReadVarint(r.r)
+	return enumArg(k, int(n)), err
+}
+
+// addSegment rebuilds a path from its segments.
+func addSegment(path *drawings.Path, s drawings.PathSegment) {
+	p := s.Points
+	switch s.Op {
+	case drawings.MOVE_TO:
+		path.MoveTo(p[0].X, p[0].Y)
+	case drawings.LINE_TO:
+		path.LineTo(p[0].X, p[0].Y)
+	case drawings.QUAD_TO:
+		path.QuadTo(p[0].X, p[0].Y, p[1].X, p[1].Y)
+	case drawings.CUBIC_TO:
+		path.CubicTo(p[0].X, p[0].Y, p[1].X, p[1].Y, p[2].X, p[2].Y)
+	case drawings.CLOSE:
+		path.Close()
+	}
+}
@@ -0,0 +1,265 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/recorder"
+)
+
+// Encoder writes commands to a stream, the header is written with the first command.
+type Encoder struct {
+	w       io.Writer
+	format  Format
+	started bool
+}
+
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{
+		w:       w,
+		format:  format,
+		started: false,
+	}
+}
+
+// Encode writes one command, commands are checked against the command set before anything is written.
+func (e *Encoder) Encode(c recorder.Command) error {
+	code, err := lookupOp(c.Name)
+	if err != nil {
+		return err
+	}
+	o := ops[code]
+	if err := checkArgs(c, o); err != nil {
+		return err
+	}
+	var message []byte
+	if e.format == JSON {
+		message, err = e.jsonMessage(c, o)
+	} else {
+		message, err = e.binaryMessage(c, o, code)
+	}
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, c.Name)
+	}
+	_, err = e.w.Write(message)
+	if err == nil {
+		e.started = true
+	}
+	return err
+}
+
+// EncodeAll writes the commands, e.g. the display list of a recorder.Recorder.
+func (e *Encoder) EncodeAll(commands []recorder.Command) error {
+	for _, c := range commands {
+		if err := e.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update writes the Update command which ends a frame.
+func (e *Encoder) Update() error {
+	return e.Encode(recorder.Command{Name: "Update"})
+}
+
+func (e *Encoder) binaryMessage(c recorder.Command, o op, code int) ([]byte, error) {
+	w := &binaryWriter{buf: make([]byte, 0, 64)}
+	if !e.started {
+		w.buf = append(w.buf, MAGIC...)
+		w.buf = append(w.buf, VERSION)
+	}
+	w.uvarint(uint64(code))
+	for i, k := range o.args {
+		if err := w.arg(k, c.Args[i]); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf, nil
+}
+
+func (e *Encoder) jsonMessage(c recorder.Command, o op) ([]byte, error) {
+	var message []byte
+	if !e.started {
+		header, _ := json.Marshal(jsonHeader{Format: JSON_FORMAT, Version: VERSION})
+		message = append(header, '\n')
+	}
+	jc := jsonCommand{Op: c.Name}
+	for i, k := range o.args {
+		v, err := jsonArg(k, c.Args[i])
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		jc.Args = append(jc.Args, raw)
+	}
+	line, err := json.Marshal(jc)
+	if err != nil {
+		return nil, err
+	}
+	message = append(message, line...)
+	return append(message, '\n'), nil
+}
+
+// Decoder reads the commands of a binary or JSON stream, the format is found from the header.
+type Decoder struct {
+	r       *bufio.Reader
+	format  Format
+	version int
+	started bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:       bufio.NewReader(r),
+		version: 0,
+		started: false,
+	}
+}
+
+func (d *Decoder) readHeader() error {
+	first, err := d.r.Peek(1)
+	if err != nil {
+		return err
+	}
+	if first[0] == '{' {
+		d.format = JSON
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var header jsonHeader
+		if json.Unmarshal(line, &header) != nil || header.Format != JSON_FORMAT {
+			return ErrBadHeader
+		}
+		d.version = header.Version
+		return checkVersion(header.Version)
+	}
+	d.format = BINARY
+	header := make([]byte, len(MAGIC)+1)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return ErrBadHeader
+	}
+	if string(header[:len(MAGIC)]) != MAGIC {
+		return ErrBadHeader
+	}
+	d.version = int(header[len(MAGIC)])
+	return checkVersion(d.version)
+}
+
+func checkVersion(version int) error {
+	if version < MIN_VERSION || version > VERSION {
+		return fmt.Errorf("protocol: version %d is not supported", version)
+	}
+	return nil
+}
+
+// Decode reads the next command, io.EOF is returned at the end of the stream.
+func (d *Decoder) Decode() (recorder.Command, error) {
+	if !d.started {
+		if err := d.readHeader(); err != nil {
+			return recorder.Command{}, err
+		}
+		d.started = true
+	}
+	if d.format == JSON {
+		return d.decodeJSON()
+	}
+	return d.decodeBinary()
+}
+
+func (d *Decoder) decodeBinary() (recorder.Command, error) {
+	code, err := readUvarint(d.r)
+	if err != nil {
+		return recorder.Command{}, err
+	}
+	if code >= uint64(len(ops)) {
+		return recorder.Command{}, fmt.Errorf("protocol: unknown op code %d", code)
+	}
+	o := ops[code]
+	c := recorder.Command{Name: o.name, Args: make([]any, len(o.args))}
+	r := &binaryReader{r: d.r}
+	for i, k := range o.args {
+		if c.Args[i], err = r.arg(k); err != nil {
+			return recorder.Command{}, fmt.Errorf("protocol: %s: %w", o.name, unexpectedEOF(err))
+		}
+	}
+	if err := checkCommandVersion(c, int(code), d.version); err != nil {
+		return recorder.Command{}, err
+	}
+	return c, nil
+}
+
+// readUvarint reads the op code of the next command, io.EOF is only returned between commands.
+func readUvarint(r *bufio.Reader) (uint64, error) {
+	if _, err := r.Peek(1); err != nil {
+		return 0, err
+	}
+	n, err := binary.ReadUvarint(r)
+	return n, unexpectedEOF(err)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (d *Decoder) decodeJSON() (recorder.Command, error) {
+	var line []byte
+	for len(line) == 0 {
+		var err error
+		line, err = d.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(bytes.TrimSpace(line)) == 0) {
+			return recorder.Command{}, err
+		}
+		line = bytes.TrimSpace(line)
+	}
+	var jc jsonCommand
+	if err := json.Unmarshal(line, &jc); err != nil {
+		return recorder.Command{}, fmt.Errorf("protocol: %w", err)
+	}
+	code, err := lookupOp(jc.Op)
+	if err != nil {
+		return recorder.Command{}, err
+	}
+	o := ops[code]
+	if len(jc.Args) != len(o.args) {
+		return recorder.Command{}, fmt.Errorf("protocol: %s has %d arguments, want %d", jc.Op, len(jc.Args), len(o.args))
+	}
+	c := recorder.Command{Name: o.name, Args: make([]any, len(o.args))}
+	for i, k := range o.args {
+		if c.Args[i], err = parseJSONArg(k, jc.Args[i]); err != nil {
+			return recorder.Command{}, fmt.Errorf("protocol: %s argument %d: %w", o.name, i+1, err)
+		}
+	}
+	if err := checkCommandVersion(c, code, d.version); err != nil {
+		return recorder.Command{}, err
+	}
+	return c, nil
+}
+
+// ApplyAll decodes the stream to its end and applies every command to the target.
+func (d *Decoder) ApplyAll(target drawings.Sketcher) error {
+	for {
+		c, err := d.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := Apply(c, target); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/recorder"
+	"github.com/marksaravi/drivers-go/colors"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+// testCommands has every op. The floats are exact in 32 bits, paths keep no arcs since ArcTo
+// stores the cubics it approximates them with.
+func testCommands() []recorder.Command {
+	points := []drawings.Point{{X: 1, Y: 2.5}, {X: -3.25, Y: 4}, {X: 100, Y: 0.125}}
+	path := drawings.NewPath().MoveTo(1, 2).LineTo(10, 2).QuadTo(12, 4, 10, 6).CubicTo(8, 8, 4, 8, 2, 6).Close()
+	linear := drawings.LinearGradient{X1: 0, Y1: 1, X2: 100, Y2: 50.5, Dither: true, Stops: []drawings.GradientStop{
+		{Offset: 0, Color: drawings.Color{R: 255}},
+		{Offset: 0.5, Color: drawings.Color{G: 128, B: 3}},
+		{Offset: 1, Color: drawings.Color{R: 1, G: 2, B: 3}},
+	}}
+	radial := drawings.RadialGradient{X: 160, Y: 120, Radius: 80, Stops: []drawings.GradientStop{
+		{Offset: 0.25, Color: drawings.Color{B: 255}},
+		{Offset: 0.75, Color: drawings.Color{R: 9, G: 8, B: 7}},
+	}}
+	kerning := drawings.KerningTable{{Left: 'A', Right: 'V'}: -2.5, {Left: 'T', Right: 'o'}: -1}
+	red := colors.RGB888(0xFF0000)
+	return []recorder.Command{
+		{Name: "SetRotation", Args: []any{1.0}},
+		{Name: "ClearArea", Args: []any{0.0, 1.0, 2.0, 3.0, red}},
+		{Name: "Clear", Args: []any{colors.RGB565(0xF81F)}},
+		{Name: "Pixel", Args: []any{1.5, 2.5, 0x123456}},
+		{Name: "Line", Args: []any{0.0, 0.0, 10.0, 20.0, drawings.Color{R: 1, G: 2, B: 3}}},
+		{Name: "Arc", Args: []any{50.0, 50.0, 20.0, -1.5, 3.0, red}},
+		{Name: "DirectedArc", Args: []any{50.0, 50.0, 20.0, 0.5, 2.0, drawings.COUNTER_CLOCKWISE, red}},
+		{Name: "ThickArc", Args: []any{50.0, 50.0, 20.0, 0.5, 2.0, 4.0, drawings.CENTER_WIDTH, red}},
+		{Name: "Circle", Args: []any{10.0, 10.0, 5.0, red}},
+		{Name: "Rectangle", Args: []any{1.0, 2.0, 3.0, 4.0, red}},
+		{Name: "FillCircle", Args: []any{10.0, 10.0, 5.0, drawings.Solid{Color: red}}},
+		{Name: "ThickCircle", Args: []any{10.0, 10.0, 5.0, 2.0, drawings.OUTER_WIDTH, red}},
+		{Name: "FillRectangle", Args: []any{1.0, 2.0, 30.0, 40.0, linear}},
+		{Name: "ThickRectangle", Args: []any{1.0, 2.0, 30.0, 40.0, 3.0, drawings.INNER_WIDTH, red}},
+		{Name: "Polyline", Args: []any{points, red}},
+		{Name: "ThickPolyline", Args: []any{points, 2.5, red}},
+		{Name: "FillPolygon", Args: []any{points, radial}},
+		{Name: "QuadBezier", Args: []any{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, red}},
+		{Name: "CubicBezier", Args: []any{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, red}},
+		{Name: "Spline", Args: []any{points, red}},
+		{Name: "ThickQuadBezier", Args: []any{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 1.5, red}},
+		{Name: "ThickCubicBezier", Args: []any{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, 1.5, red}},
+		{Name: "ThickSpline", Args: []any{points, 3.0, red}},
+		{Name: "FillSpline", Args: []any{points, red}},
+		{Name: "StrokePath", Args: []any{path, 2.0, red}},
+		{Name: "FillPath", Args: []any{path, drawings.EVEN_ODD, linear}},
+		{Name: "SetFont", Args: []any{fonts.FreeSans12pt7b}},
+		{Name: "WriteScaled", Args: []any{"Hello", 2.0, 1.5, red}},
+		{Name: "Write", Args: []any{"Hello \"world\"\n", red}},
+		{Name: "WriteRotated", Args: []any{"Hi", 0.5, red}},
+		{Name: "WriteRotatedScaled", Args: []any{"Hi", 2.0, 2.0, -0.5, radial}},
+		{Name: "MoveCursor", Args: []any{10.0, 20.0}},
+		{Name: "SetFontSampling", Args: []any{drawings.AREA_SAMPLING}},
+		{Name: "SetFontSmoothing", Args: []any{true, colors.RGB888(0x000010)}},
+		{Name: "SetLetterSpacing", Args: []any{1.5}},
+		{Name: "SetWordSpacing", Args: []any{-0.5}},
+		{Name: "SetKerning", Args: []any{kerning}},
+		{Name: "SetKerning", Args: []any{drawings.KerningTable(nil)}},
+		{Name: "FillRectangleGradient", Args: []any{0.0, 0.0, 100.0, 50.0, red, drawings.Color{B: 200}, true}},
+		{Name: "Update"},
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, format := range []Format{BINARY, JSON} {
+		var b bytes.Buffer
+		commands := testCommands()
+		if err := NewEncoder(&b, format).EncodeAll(commands); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		d := NewDecoder(&b)
+		for _, want := range commands {
+			got, err := d.Decode()
+			if err != nil {
+				t.Fatalf("format %d: %s: %v", format, want.Name, err)
+			}
+			if got.Name != want.Name || len(got.Args) != len(want.Args) {
+				t.Errorf("format %d: decoded %s(%d args), want %s(%d args)", format, got.Name, len(got.Args), want.Name, len(want.Args))
+				continue
+			}
+			for i := range want.Args {
+				if !sameArg(got.Args[i], want.Args[i]) {
+					t.Errorf("format %d: %s argument %d = %#v, want %#v", format, want.Name, i, got.Args[i], want.Args[i])
+				}
+			}
+		}
+		if _, err := d.Decode(); err != io.EOF {
+			t.Errorf("format %d: decoding after the last command: %v", format, err)
+		}
+	}
+}
+
+// sameArg compares decoded arguments, an empty kerning table is sent like none.
+func sameArg(got, want any) bool {
+	if k, ok := want.(drawings.KerningTable); ok && len(k) == 0 {
+		g, ok := got.(drawings.KerningTable)
+		return ok && len(g) == 0
+	}
+	if f, ok := want.(fonts.BitmapFont); ok {
+		return sameFont(got, f)
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func TestEncodeRejectsBadCommands(t *testing.T) {
+	bad := []recorder.Command{
+		{Name: "Explode"},
+		{Name: "Line", Args: []any{1.0, 2.0, 3.0}},
+		{Name: "Line", Args: []any{1.0, 2.0, 3.0, "4", 0xFF}},
+		{Name: "Pixel", Args: []any{1.0, 2.0, struct{}{}}},
+		{Name: "SetFont", Args: []any{fonts.BitmapFont{}}},
+	}
+	for _, format := range []Format{BINARY, JSON} {
+		for _, c := range bad {
+			var b bytes.Buffer
+			if err := NewEncoder(&b, format).Encode(c); err == nil {
+				t.Errorf("format %d: %s%v encoded", format, c.Name, c.Args)
+			}
+			if b.Len() != 0 {
+				t.Errorf("format %d: %s%v wrote %d bytes", format, c.Name, c.Args, b.Len())
+			}
+		}
+	}
+}
+
+// encodeVersion encodes the command and rewrites the version of the stream header.
+func encodeVersion(t *testing.T, format Format, version int, c recorder.Command) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := NewEncoder(&b, format).Encode(c); err != nil {
+		t.Fatal(err)
+	}
+	if format == JSON {
+		return bytes.Replace(b.Bytes(), []byte(`"version":2`), []byte(fmt.Sprintf(`"version":%d`, version)), 1)
+	}
+	stream := b.Bytes()
+	stream[len(MAGIC)] = byte(version)
+	return stream
+}
+
+func TestDecodeVersions(t *testing.T) {
+	tests := []struct {
+		version int
+		command recorder.Command
+		ok      bool
+	}{
+		{1, recorder.Command{Name: "Line", Args: []any{0.0, 0.0, 1.0, 1.0, colors.RGB888(0xFF0000)}}, true},
+		{1, recorder.Command{Name: "Line", Args: []any{0.0, 0.0, 1.0, 1.0, 0xFF0000}}, true},
+		{1, recorder.Command{Name: "Line", Args: []any{0.0, 0.0, 1.0, 1.0, drawings.Color{R: 255}}}, false},
+		{1, recorder.Command{Name: "FillCircle", Args: []any{1.0, 1.0, 1.0, drawings.Solid{Color: 0xFF}}}, false},
+		{1, recorder.Command{Name: "FillRectangleGradient", Args: []any{0.0, 0.0, 1.0, 1.0, 0, 0xFF, false}}, false},
+		{2, recorder.Command{Name: "FillRectangleGradient", Args: []any{0.0, 0.0, 1.0, 1.0, 0, 0xFF, false}}, true},
+		{0, recorder.Command{Name: "Update"}, false},
+		{VERSION + 1, recorder.Command{Name: "Update"}, false},
+	}
+	for _, format := range []Format{BINARY, JSON} {
+		for _, test := range tests {
+			stream := encodeVersion(t, format, test.version, test.command)
+			_, err := NewDecoder(bytes.NewReader(stream)).Decode()
+			if test.ok && err != nil {
+				t.Errorf("format %d version %d: %s: %v", format, test.version, test.command.Name, err)
+			}
+			if !test.ok && err == nil {
+				t.Errorf("format %d version %d: %s%v decoded", format, test.version, test.command.Name, test.command.Args)
+			}
+		}
+	}
+}
+
+func TestPaintInSolid(t *testing.T) {
+	nested := recorder.Command{Name: "Clear", Args: []any{drawings.Solid{Color: drawings.Solid{Color: 0xFF}}}}
+	for _, format := range []Format{BINARY, JSON} {
+		if err := NewEncoder(io.Discard, format).Encode(nested); err == nil {
+			t.Errorf("format %d: a solid of a solid encoded", format)
+		}
+	}
+
+	binaryStream := append([]byte(MAGIC), VERSION, byte(opCodes["Clear"]))
+	for i := 0; i < 100000; i++ {
+		binaryStream = append(binaryStream, colorSolid)
+	}
+	binaryStream = append(binaryStream, colorInt, 0)
+	jsonStream := fmt.Sprintf("{\"format\":%q,\"version\":%d}\n{\"op\":\"Clear\",\"args\":[%s{\"int\":0}%s]}\n",
+		JSON_FORMAT, VERSION, strings.Repeat(`{"solid":`, 100), strings.Repeat("}", 100))
+	for _, stream := range [][]byte{binaryStream, []byte(jsonStream)} {
+		if _, err := NewDecoder(bytes.NewReader(stream)).Decode(); !errors.Is(err, errPaintInSolid) {
+			t.Errorf("decoding nested solids: %v", err)
+		}
+	}
+}
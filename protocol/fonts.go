@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"sync"
+
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+var (
+	fontsMu     sync.RWMutex
+	fontsByName = map[string]any{}
+)
+
+// RegisterFont makes a font known to encoders and decoders by name. The fonts of
+// fonts-go are registered with their variable names, e.g. "FreeSans12pt7b".
+func RegisterFont(name string, font any) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+	fontsByName[name] = font
+}
+
+func fontByName(name string) (any, error) {
+	fontsMu.RLock()
+	defer fontsMu.RUnlock()
+	font, ok := fontsByName[name]
+	if !ok {
+		return nil, ErrUnknownFont
+	}
+	return font, nil
+}
+
+// fontName finds the name of a registered font, a string is taken as the name itself.
+func fontName(font any) (string, error) {
+	if name, ok := font.(string); ok {
+		if _, err := fontByName(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	fontsMu.RLock()
+	defer fontsMu.RUnlock()
+	for name, registered := range fontsByName {
+		if sameFont(font, registered) {
+			return name, nil
+		}
+	}
+	return "", ErrUnknownFont
+}
+
+// sameFont compares bitmap fonts by their bitmap data, which is shared by all copies of a font.
+func sameFont(a, b any) bool {
+	fa, ok1 := a.(fonts.BitmapFont)
+	fb, ok2 := b.(fonts.BitmapFont)
+	if !ok1 || !ok2 || len(fa.Bitmap) == 0 || len(fa.Bitmap) != len(fb.Bitmap) || len(fa.Glyphs) != len(fb.Glyphs) {
+		return false
+	}
+	return &fa.Bitmap[0] == &fb.Bitmap[0]
+}
+
+func init() {
+	for name, font := range map[string]fonts.BitmapFont{
+		"FreeMono12pt7b":            fonts.FreeMono12pt7b,
+		"FreeMono18pt7b":            fonts.FreeMono18pt7b,
+		"FreeMono24pt7b":            fonts.FreeMono24pt7b,
+		"FreeMono9pt7b":             fonts.FreeMono9pt7b,
+		"FreeMonoBold12pt7b":        fonts.FreeMonoBold12pt7b,
+		"FreeMonoBold18pt7b":        fonts.FreeMonoBold18pt7b,
+		"FreeMonoBold24pt7b":        fonts.FreeMonoBold24pt7b,
+		"FreeMonoBold9pt7b":         fonts.FreeMonoBold9pt7b,
+		"FreeMonoBoldOblique12pt7b": fonts.FreeMonoBoldOblique12pt7b,
+		"FreeMonoBoldOblique18pt7b": fonts.FreeMonoBoldOblique18pt7b,
+		"FreeMonoBoldOblique24pt7b": fonts.FreeMonoBoldOblique24pt7b,
+		"FreeMonoBoldOblique9pt7b":  fonts.FreeMonoBoldOblique9pt7b,
+		"FreeMonoOblique12pt7b":     fonts.FreeMonoOblique12pt7b,
+		"FreeMonoOblique18pt7b":     fonts.FreeMonoOblique18pt7b,
+		"FreeMonoOblique24pt7b":     fonts.FreeMonoOblique24pt7b,
+		"FreeMonoOblique9pt7b":      fonts.FreeMonoOblique9pt7b,
+		"FreeSans12pt7b":            fonts.FreeSans12pt7b,
+		"FreeSans18pt7b":            fonts.FreeSans18pt7b,
+		"FreeSans24pt7b":            fonts.FreeSans24pt7b,
+		"FreeSans9pt7b":             fonts.FreeSans9pt7b,
+		"FreeSansBold12pt7b":        fonts.FreeSansBold12pt7b,
+		"FreeSansBold18pt7b":        fonts.FreeSansBold18pt7b,
+		"FreeSansBold24pt7b":        fonts.FreeSansBold24pt7b,
+		"FreeSansBold9pt7b":         fonts.FreeSansBold9pt7b,
+		"FreeSansBoldOblique12pt7b": fonts.FreeSansBoldOblique12pt7b,
+		"FreeSansBoldOblique18pt7b": fonts.FreeSansBoldOblique18pt7b,
+		"FreeSansBoldOblique24pt7b": fonts.FreeSansBoldOblique24pt7b,
+		"FreeSansBoldOblique9pt7b":  fonts.FreeSansBoldOblique9pt7b,
+		"FreeSansOblique12pt7b":     fonts.FreeSansOblique12pt7b,
+		"FreeSansOblique18pt7b":     fonts.FreeSansOblique18pt7b,
+		"FreeSansOblique24pt7b":     fonts.FreeSansOblique24pt7b,
+		"FreeSansOblique9pt7b":      fonts.FreeSansOblique9pt7b,
+		"FreeSerif12pt7b":           fonts.FreeSerif12pt7b,
+		"FreeSerif18pt7b":           fonts.FreeSerif18pt7b,
+		"FreeSerif24pt7b":           fonts.FreeSerif24pt7b,
+		"FreeSerif9pt7b":            fonts.FreeSerif9pt7b,
+		"FreeSerifBold12pt7b":       fonts.FreeSerifBold12pt7b,
+		"FreeSerifBold18pt7b":       fonts.FreeSerifBold18pt7b,
+		"FreeSerifBold24pt7b":       fonts.FreeSerifBold24pt7b,
+		"FreeSerifBold9pt7b":        fonts.FreeSerifBold9pt7b,
+		"FreeSerifBoldItalic12pt7b": fonts.FreeSerifBoldItalic12pt7b,
+		"FreeSerifBoldItalic18pt7b": fonts.FreeSerifBoldItalic18pt7b,
+		"FreeSerifBoldItalic24pt7b": fonts.FreeSerifBoldItalic24pt7b,
+		"FreeSerifBoldItalic9pt7b":  fonts.FreeSerifBoldItalic9pt7b,
+		"FreeSerifItalic12pt7b":     fonts.FreeSerifItalic12pt7b,
+		"FreeSerifItalic18pt7b":     fonts.FreeSerifItalic18pt7b,
+		"FreeSerifItalic24pt7b":     fonts.FreeSerifItalic24pt7b,
+		"FreeSerifItalic9pt7b":      fonts.FreeSerifItalic9pt7b,
+		"Picopixel":                 fonts.Picopixel,
+	} {
+		RegisterFont(name, font)
+	}
+}
@@ -0,0 +1,326 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+type jsonHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+type jsonCommand struct {
+	Op   string            `json:"op"`
+	Args []json.RawMessage `json:"args,omitempty"`
+}
+
+// jsonColor is null or an object with one of its fields set.
+type jsonColor struct {
+	RGB888 *string         `json:"rgb888,omitempty"`
+	RGB565 *uint16         `json:"rgb565,omitempty"`
+	Int    *int            `json:"int,omitempty"`
+	Color  *string         `json:"color,omitempty"`
+	Solid  json.RawMessage `json:"solid,omitempty"`
+	Linear *jsonLinear     `json:"linear,omitempty"`
+	Radial *jsonRadial     `json:"radial,omitempty"`
+}
+
+type jsonStop struct {
+	Offset float64 `json:"offset"`
+	Color  string  `json:"color"`
+}
+
+type jsonLinear struct {
+	X1     float64    `json:"x1"`
+	Y1     float64    `json:"y1"`
+	X2     float64    `json:"x2"`
+	Y2     float64    `json:"y2"`
+	Stops  []jsonStop `json:"stops"`
+	Dither bool       `json:"dither,omitempty"`
+}
+
+type jsonRadial struct {
+	X      float64    `json:"x"`
+	Y      float64    `json:"y"`
+	Radius float64    `json:"r"`
+	Stops  []jsonStop `json:"stops"`
+	Dither bool       `json:"dither,omitempty"`
+}
+
+func jsonStops(stops []drawings.GradientStop) []jsonStop {
+	s := make([]jsonStop, len(stops))
+	for i, stop := range stops {
+		s[i] = jsonStop{Offset: stop.Offset, Color: stop.Color.String()}
+	}
+	return s
+}
+
+func parseJSONStops(s []jsonStop) ([]drawings.GradientStop, error) {
+	stops := make([]drawings.GradientStop, len(s))
+	for i, stop := range s {
+		c, err := drawings.ParseColor(stop.Color)
+		if err != nil {
+			return nil, fmt.Errorf("protocol: %w", err)
+		}
+		stops[i] = drawings.GradientStop{Offset: stop.Offset, Color: c}
+	}
+	return stops, nil
+}
+
+var pathOpNames = map[drawings.PathOp]string{
+	drawings.MOVE_TO:  "M",
+	drawings.LINE_TO:  "L",
+	drawings.QUAD_TO:  "Q",
+	drawings.CUBIC_TO: "C",
+	drawings.CLOSE:    "Z",
+}
+
+// jsonArg converts an argument to the value marshalled for it.
+func jsonArg(k kind, v any) (any, error) {
+	mismatch := fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	switch k {
+	case kindFloat:
+		if _, ok := v.(float64); !ok {
+			return nil, mismatch
+		}
+		return v, nil
+	case kindString:
+		if _, ok := v.(string); !ok {
+			return nil, mismatch
+		}
+		return v, nil
+	case kindBool:
+		if _, ok := v.(bool); !ok {
+			return nil, mismatch
+		}
+		return v, nil
+	case kindColor:
+		switch c := v.(type) {
+		case nil:
+			return nil, nil
+		case colors.RGB888:
+			s := fmt.Sprintf("#%06x", uint32(c)&0xFFFFFF)
+			return jsonColor{RGB888: &s}, nil
+		case colors.RGB565:
+			n := uint16(c)
+			return jsonColor{RGB565: &n}, nil
+		case int:
+			return jsonColor{Int: &c}, nil
+		case drawings.Color:
+			s := c.String()
+			return jsonColor{Color: &s}, nil
+		case drawings.Solid:
+			if _, ok := c.Color.(drawings.Paint); ok {
+				return nil, errPaintInSolid
+			}
+			inner, err := jsonArg(kindColor, c.Color)
+			if err != nil {
+				return nil, err
+			}
+			raw, err := json.Marshal(inner)
+			return jsonColor{Solid: raw}, err
+		case drawings.LinearGradient:
+			return jsonColor{Linear: &jsonLinear{X1: c.X1, Y1: c.Y1, X2: c.X2, Y2: c.Y2, Stops: jsonStops(c.Stops), Dither: c.Dither}}, nil
+		case drawings.RadialGradient:
+			return jsonColor{Radial: &jsonRadial{X: c.X, Y: c.Y, Radius: c.Radius, Stops: jsonStops(c.Stops), Dither: c.Dither}}, nil
+		}
+		return nil, fmt.Errorf("%w: color %T", ErrUnsupportedType, v)
+	case kindPoints:
+		points, ok := v.([]drawings.Point)
+		if !ok {
+			return nil, mismatch
+		}
+		xy := make([][2]float64, len(points))
+		for i, p := range points {
+			xy[i] = [2]float64{p.X, p.Y}
+		}
+		return xy, nil
+	case kindPath:
+		path, ok := v.(*drawings.Path)
+		if !ok || path == nil {
+			return nil, mismatch
+		}
+		segments := make([][]any, 0)
+		for _, s := range path.Segments() {
+			segment := []any{pathOpNames[s.Op]}
+			for _, p := range s.Points {
+				segment = append(segment, p.X, p.Y)
+			}
+			segments = append(segments, segment)
+		}
+		return segments, nil
+	case kindFont:
+		return fontName(v)
+	case kindKerning:
+		table, ok := v.(drawings.KerningTable)
+		if !ok && v != nil {
+			return nil, mismatch
+		}
+		pairs := make([][3]any, 0, len(table))
+		for pair, offset := range table {
+			pairs = append(pairs, [3]any{string(pair.Left), string(pair.Right), offset})
+		}
+		return pairs, nil
+	}
+	n, ok := enumValue(k, v)
+	if !ok {
+		return nil, mismatch
+	}
+	return n, nil
+}
+
+// parseJSONArg converts a JSON value back to an argument of the given kind.
+func parseJSONArg(k kind, raw json.RawMessage) (any, error) {
+	switch k {
+	case kindFloat:
+		var f float64
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	case kindString:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case kindBool:
+		var b bool
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	case kindColor:
+		var c *jsonColor
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, err
+		}
+		switch {
+		case c == nil:
+			return nil, nil
+		case c.RGB888 != nil:
+			rgb, err := drawings.ParseColor(*c.RGB888)
+			if err != nil {
+				return nil, fmt.Errorf("protocol: %w", err)
+			}
+			return colors.RGB888(rgb.RGB888()), nil
+		case c.RGB565 != nil:
+			return colors.RGB565(*c.RGB565), nil
+		case c.Int != nil:
+			return *c.Int, nil
+		case c.Color != nil:
+			rgb, err := drawings.ParseColor(*c.Color)
+			if err != nil {
+				return nil, fmt.Errorf("protocol: %w", err)
+			}
+			return rgb, nil
+		case c.Solid != nil:
+			// a solid holds a plain colour, so the colours are only parsed one level deep
+			var plain *jsonColor
+			if err := json.Unmarshal(c.Solid, &plain); err != nil {
+				return nil, err
+			}
+			if plain != nil && (plain.Solid != nil || plain.Linear != nil || plain.Radial != nil) {
+				return nil, errPaintInSolid
+			}
+			inner, err := parseJSONArg(kindColor, c.Solid)
+			return drawings.Solid{Color: inner}, err
+		case c.Linear != nil:
+			stops, err := parseJSONStops(c.Linear.Stops)
+			l := c.Linear
+			return drawings.LinearGradient{X1: l.X1, Y1: l.Y1, X2: l.X2, Y2: l.Y2, Stops: stops, Dither: l.Dither}, err
+		case c.Radial != nil:
+			stops, err := parseJSONStops(c.Radial.Stops)
+			r := c.Radial
+			return drawings.RadialGradient{X: r.X, Y: r.Y, Radius: r.Radius, Stops: stops, Dither: r.Dither}, err
+		}
+		return nil, fmt.Errorf("protocol: invalid color %s", raw)
+	case kindPoints:
+		var xy [][2]float64
+		if err := json.Unmarshal(raw, &xy); err != nil {
+			return nil, err
+		}
+		points := make([]drawings.Point, len(xy))
+		for i, p := range xy {
+			points[i] = drawings.Point{X: p[0], Y: p[1]}
+		}
+		return points, nil
+	case kindPath:
+		var segments [][]json.RawMessage
+		if err := json.Unmarshal(raw, &segments); err != nil {
+			return nil, err
+		}
+		path := drawings.NewPath()
+		for _, values := range segments {
+			segment, err := parseJSONSegment(values)
+			if err != nil {
+				return nil, err
+			}
+			addSegment(path, segment)
+		}
+		return path, nil
+	case kindFont:
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return nil, err
+		}
+		return fontByName(name)
+	case kindKerning:
+		var pairs [][3]json.RawMessage
+		if err := json.Unmarshal(raw, &pairs); err != nil || len(pairs) == 0 {
+			return drawings.KerningTable(nil), err
+		}
+		table := make(drawings.KerningTable, len(pairs))
+		for _, p := range pairs {
+			var left, right string
+			var offset float64
+			if err := json.Unmarshal(p[0], &left); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(p[1], &right); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(p[2], &offset); err != nil {
+				return nil, err
+			}
+			if len(left) != 1 || len(right) != 1 {
+				return nil, fmt.Errorf("protocol: invalid kerning pair %q %q", left, right)
+			}
+			table[drawings.KerningPair{Left: left[0], Right: right[0]}] = offset
+		}
+		return table, nil
+	}
+	var n int
+	err := json.Unmarshal(raw, &n)
+	return enumArg(k, n), err
+}
+
+func parseJSONSegment(values []json.RawMessage) (drawings.PathSegment, error) {
+	var segment drawings.PathSegment
+	if len(values) == 0 {
+		return segment, fmt.Errorf("protocol: empty path segment")
+	}
+	var name string
+	if err := json.Unmarshal(values[0], &name); err != nil {
+		return segment, err
+	}
+	found := false
+	for op, opName := range pathOpNames {
+		if opName == name {
+			segment.Op = op
+			found = true
+		}
+	}
+	if !found || len(values) != 1+2*pathPoints[segment.Op] {
+		return segment, fmt.Errorf("protocol: invalid path segment %q", name)
+	}
+	for i := 1; i < len(values); i += 2 {
+		var x, y float64
+		if err := json.Unmarshal(values[i], &x); err != nil {
+			return segment, err
+		}
+		if err := json.Unmarshal(values[i+1], &y); err != nil {
+			return segment, err
+		}
+		segment.Points = append(segment.Points, drawings.Point{X: x, Y: y})
+	}
+	return segment, nil
+}
@@ -0,0 +1,170 @@
+// Package protocol serialises Sketcher calls so a drawing can be sent to files, pipes or
+// sockets and applied to a Sketcher in another process.
+//
+// A stream starts with a header holding the format version, followed by one message per
+// command. The binary format is "DRWP", the version byte and for every command its op code
+// and arguments; floats are sent as 32 bit little endian floats, integers and lengths as
+// varints and strings with their length. The JSON format has a header line
+// {"format":"drawings","version":2} and one {"op":"Line","args":[...]} object per line.
+// Fonts are sent by the name they are registered with on both ends, see RegisterFont.
+// A receiver may answer every Update command with an ack, see WriteAck.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/recorder"
+)
+
+type Format int
+
+const (
+	BINARY Format = 0
+	JSON   Format = 1
+)
+
+// VERSION is the version of the wire format written by the encoder. Decoders accept
+// streams of MIN_VERSION to VERSION. Version 2 added drawings.Color, the paints and
+// FillRectangleGradient.
+const (
+	VERSION     = 2
+	MIN_VERSION = 1
+)
+
+const (
+	MAGIC       = "DRWP"
+	JSON_FORMAT = "drawings"
+)
+
+var (
+	ErrBadHeader       = errors.New("protocol: not a drawing command stream")
+	ErrUnknownFont     = errors.New("protocol: font is not registered")
+	ErrUnsupportedType = errors.New("protocol: unsupported argument type")
+	errPaintInSolid    = errors.New("protocol: a solid paint holds a paint")
+)
+
+type kind int
+
+const (
+	kindFloat kind = iota
+	kindString
+	kindBool
+	kindColor
+	kindPoints
+	kindPath
+	kindFont
+	kindKerning
+	kindWidthType
+	kindDirection
+	kindFillRule
+	kindSampling
+)
+
+type op struct {
+	name string
+	args []kind
+}
+
+// short names for the table below
+const (
+	fl = kindFloat
+	co = kindColor
+)
+
+// ops is the command set, the op code of a command is its index. New commands are only
+// appended, the commands of a version are the first versionOps of it.
+var ops = []op{
+	{"Update", nil},
+	{"SetRotation", []kind{fl}},
+	{"ClearArea", []kind{fl, fl, fl, fl, co}},
+	{"Clear", []kind{co}},
+	{"Pixel", []kind{fl, fl, co}},
+	{"Line", []kind{fl, fl, fl, fl, co}},
+	{"Arc", []kind{fl, fl, fl, fl, fl, co}},
+	{"DirectedArc", []kind{fl, fl, fl, fl, fl, kindDirection, co}},
+	{"ThickArc", []kind{fl, fl, fl, fl, fl, fl, kindWidthType, co}},
+	{"Circle", []kind{fl, fl, fl, co}},
+	{"Rectangle", []kind{fl, fl, fl, fl, co}},
+	{"FillCircle", []kind{fl, fl, fl, co}},
+	{"ThickCircle", []kind{fl, fl, fl, fl, kindWidthType, co}},
+	{"FillRectangle", []kind{fl, fl, fl, fl, co}},
+	{"ThickRectangle", []kind{fl, fl, fl, fl, fl, kindWidthType, co}},
+	{"Polyline", []kind{kindPoints, co}},
+	{"ThickPolyline", []kind{kindPoints, fl, co}},
+	{"FillPolygon", []kind{kindPoints, co}},
+	{"QuadBezier", []kind{fl, fl, fl, fl, fl, fl, co}},
+	{"CubicBezier", []kind{fl, fl, fl, fl, fl, fl, fl, fl, co}},
+	{"Spline", []kind{kindPoints, co}},
+	{"ThickQuadBezier", []kind{fl, fl, fl, fl, fl, fl, fl, co}},
+	{"ThickCubicBezier", []kind{fl, fl, fl, fl, fl, fl, fl, fl, fl, co}},
+	{"ThickSpline", []kind{kindPoints, fl, co}},
+	{"FillSpline", []kind{kindPoints, co}},
+	{"StrokePath", []kind{kindPath, fl, co}},
+	{"FillPath", []kind{kindPath, kindFillRule, co}},
+	{"SetFont", []kind{kindFont}},
+	{"WriteScaled", []kind{kindString, fl, fl, co}},
+	{"Write", []kind{kindString, co}},
+	{"WriteRotated", []kind{kindString, fl, co}},
+	{"WriteRotatedScaled", []kind{kindString, fl, fl, fl, co}},
+	{"MoveCursor", []kind{fl, fl}},
+	{"SetFontSampling", []kind{kindSampling}},
+	{"SetFontSmoothing", []kind{kindBool, co}},
+	{"SetLetterSpacing", []kind{fl}},
+	{"SetWordSpacing", []kind{fl}},
+	{"SetKerning", []kind{kindKerning}},
+	{"FillRectangleGradient", []kind{fl, fl, fl, fl, co, co, kindBool}},
+}
+
+var versionOps = map[int]int{1: 38, 2: len(ops)}
+
+var opCodes = func() map[string]int {
+	codes := make(map[string]int, len(ops))
+	for i, o := range ops {
+		codes[o.name] = i
+	}
+	return codes
+}()
+
+func lookupOp(name string) (int, error) {
+	code, ok := opCodes[name]
+	if !ok {
+		return 0, fmt.Errorf("protocol: unknown command %q", name)
+	}
+	return code, nil
+}
+
+// checkArgs tells if the command has the arguments of its op.
+func checkArgs(c recorder.Command, o op) error {
+	if len(c.Args) != len(o.args) {
+		return fmt.Errorf("protocol: %s has %d arguments, want %d", c.Name, len(c.Args), len(o.args))
+	}
+	return nil
+}
+
+// checkCommandVersion tells if a decoded command is in the version of its stream.
+func checkCommandVersion(c recorder.Command, code, version int) error {
+	if code >= versionOps[version] {
+		return fmt.Errorf("protocol: %s is not in version %d", c.Name, version)
+	}
+	if version >= 2 {
+		return nil
+	}
+	for _, arg := range c.Args {
+		switch arg.(type) {
+		case drawings.Color, drawings.Solid, drawings.LinearGradient, drawings.RadialGradient:
+			return fmt.Errorf("protocol: %s: %T is not in version %d", c.Name, arg, version)
+		}
+	}
+	return nil
+}
+
+// Apply applies a decoded command to the target, Update commands call target.Update.
+func Apply(c recorder.Command, target drawings.Sketcher) error {
+	if c.Name == "Update" {
+		target.Update()
+		return nil
+	}
+	return c.Apply(target)
+}
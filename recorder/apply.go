@@ -0,0 +1,282 @@
+package recorder
+
+import (
+	"fmt"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// args reads the arguments of a command, the first mismatch is kept in err.
+type args struct {
+	command Command
+	next    int
+	err     error
+}
+
+func (a *args) arg() any {
+	if a.next >= len(a.command.Args) {
+		if a.err == nil {
+			a.err = fmt.Errorf("%s: missing argument %d", a.command.Name, a.next+1)
+		}
+		a.next++
+		return nil
+	}
+	a.next++
+	return a.command.Args[a.next-1]
+}
+
+func (a *args) mismatch(value any, want string) {
+	if a.err == nil {
+		a.err = fmt.Errorf("%s: argument %d is %T, want %s", a.command.Name, a.next, value, want)
+	}
+}
+
+func (a *args) float() float64 {
+	v := a.arg()
+	f, ok := v.(float64)
+	if !ok {
+		a.mismatch(v, "float64")
+	}
+	return f
+}
+
+func (a *args) text() string {
+	v := a.arg()
+	s, ok := v.(string)
+	if !ok {
+		a.mismatch(v, "string")
+	}
+	return s
+}
+
+func (a *args) boolean() bool {
+	v := a.arg()
+	b, ok := v.(bool)
+	if !ok {
+		a.mismatch(v, "bool")
+	}
+	return b
+}
+
+func (a *args) points() []drawings.Point {
+	v := a.arg()
+	p, ok := v.([]drawings.Point)
+	if !ok {
+		a.mismatch(v, "[]drawings.Point")
+	}
+	return p
+}
+
+func (a *args) path() *drawings.Path {
+	v := a.arg()
+	p, ok := v.(*drawings.Path)
+	if !ok || p == nil {
+		a.mismatch(v, "*drawings.Path")
+		return drawings.NewPath()
+	}
+	return p
+}
+
+func (a *args) widthType() drawings.WidthType {
+	v := a.arg()
+	w, ok := v.(drawings.WidthType)
+	if !ok {
+		a.mismatch(v, "drawings.WidthType")
+	}
+	return w
+}
+
+func (a *args) direction() drawings.ArcDirection {
+	v := a.arg()
+	d, ok := v.(drawings.ArcDirection)
+	if !ok {
+		a.mismatch(v, "drawings.ArcDirection")
+	}
+	return d
+}
+
+func (a *args) fillRule() drawings.FillRule {
+	v := a.arg()
+	r, ok := v.(drawings.FillRule)
+	if !ok {
+		a.mismatch(v, "drawings.FillRule")
+	}
+	return r
+}
+
+func (a *args) sampling() drawings.FontSampling {
+	v := a.arg()
+	s, ok := v.(drawings.FontSampling)
+	if !ok {
+		a.mismatch(v, "drawings.FontSampling")
+	}
+	return s
+}
+
+func (a *args) kerning() drawings.KerningTable {
+	v := a.arg()
+	k, ok := v.(drawings.KerningTable)
+	if !ok && v != nil {
+		a.mismatch(v, "drawings.KerningTable")
+	}
+	return k
+}
+
+// done checks that all the arguments have been used.
+func (a *args) done() error {
+	if a.err == nil && a.next < len(a.command.Args) {
+		a.err = fmt.Errorf("%s: %d arguments, want %d", a.command.Name, len(a.command.Args), a.next)
+	}
+	return a.err
+}
+
+// Apply calls the method of the command on the target. Nothing is drawn when the
+// arguments do not match the method.
+func (c Command) Apply(target drawings.Sketcher) error {
+	call, err := c.bind(target)
+	if err != nil {
+		return err
+	}
+	return call()
+}
+
+// Check tells if the arguments match the method of the command without drawing it. Colours
+// and fonts are only checked by the Sketcher when the command is applied.
+func (c Command) Check() error {
+	_, err := c.bind(nil)
+	return err
+}
+
+// bind reads the arguments of the command and returns the call of its method on the target.
+func (c Command) bind(target drawings.Sketcher) (func() error, error) {
+	a := &args{command: c}
+	var call func()
+	switch c.Name {
+	case "SetRotation":
+		rotation := a.float()
+		call = func() { target.SetRotation(rotation) }
+	case "ClearArea":
+		x1, y1, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.ClearArea(x1, y1, x2, y2, color) }
+	case "Clear":
+		color := a.arg()
+		call = func() { target.Clear(color) }
+	case "Pixel":
+		x, y, color := a.float(), a.float(), a.arg()
+		call = func() { target.Pixel(x, y, color) }
+	case "Line":
+		x1, y1, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.Line(x1, y1, x2, y2, color) }
+	case "Arc":
+		xc, yc, radius, start, end, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.Arc(xc, yc, radius, start, end, color) }
+	case "DirectedArc":
+		xc, yc, radius, start, end, direction, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.direction(), a.arg()
+		call = func() { target.DirectedArc(xc, yc, radius, start, end, direction, color) }
+	case "ThickArc":
+		xc, yc, radius, start, end, width, widthType, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.widthType(), a.arg()
+		call = func() { target.ThickArc(xc, yc, radius, start, end, width, widthType, color) }
+	case "Circle":
+		x, y, radius, color := a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.Circle(x, y, radius, color) }
+	case "Rectangle":
+		x1, y1, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.Rectangle(x1, y1, x2, y2, color) }
+	case "FillCircle":
+		x, y, radius, color := a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.FillCircle(x, y, radius, color) }
+	case "ThickCircle":
+		x, y, radius, width, widthType, color := a.float(), a.float(), a.float(), a.float(), a.widthType(), a.arg()
+		call = func() { target.ThickCircle(x, y, radius, width, widthType, color) }
+	case "FillRectangle":
+		x1, y1, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.FillRectangle(x1, y1, x2, y2, color) }
+	case "FillRectangleGradient":
+		x1, y1, x2, y2, from, to, vertical := a.float(), a.float(), a.float(), a.float(), a.arg(), a.arg(), a.boolean()
+		call = func() { target.FillRectangleGradient(x1, y1, x2, y2, from, to, vertical) }
+	case "ThickRectangle":
+		x1, y1, x2, y2, width, widthType, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.widthType(), a.arg()
+		call = func() { target.ThickRectangle(x1, y1, x2, y2, width, widthType, color) }
+	case "Polyline":
+		points, color := a.points(), a.arg()
+		call = func() { target.Polyline(points, color) }
+	case "ThickPolyline":
+		points, width, color := a.points(), a.float(), a.arg()
+		call = func() { target.ThickPolyline(points, width, color) }
+	case "FillPolygon":
+		points, color := a.points(), a.arg()
+		call = func() { target.FillPolygon(points, color) }
+	case "QuadBezier":
+		x1, y1, cx, cy, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.QuadBezier(x1, y1, cx, cy, x2, y2, color) }
+	case "CubicBezier":
+		x1, y1, cx1, cy1, cx2, cy2, x2, y2, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2, color) }
+	case "Spline":
+		points, color := a.points(), a.arg()
+		call = func() { target.Spline(points, color) }
+	case "ThickQuadBezier":
+		x1, y1, cx, cy, x2, y2, width, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.ThickQuadBezier(x1, y1, cx, cy, x2, y2, width, color) }
+	case "ThickCubicBezier":
+		x1, y1, cx1, cy1, cx2, cy2, x2, y2, width, color := a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2, width, color) }
+	case "ThickSpline":
+		points, width, color := a.points(), a.float(), a.arg()
+		call = func() { target.ThickSpline(points, width, color) }
+	case "FillSpline":
+		points, color := a.points(), a.arg()
+		call = func() { target.FillSpline(points, color) }
+	case "StrokePath":
+		path, width, color := a.path(), a.float(), a.arg()
+		call = func() { target.StrokePath(path, width, color) }
+	case "FillPath":
+		path, rule, color := a.path(), a.fillRule(), a.arg()
+		call = func() { target.FillPath(path, rule, color) }
+	case "SetFont":
+		font := a.arg()
+		if err := a.done(); err != nil {
+			return nil, err
+		}
+		return func() error { return target.SetFont(font) }, nil
+	case "WriteScaled":
+		text, xscale, yscale, color := a.text(), a.float(), a.float(), a.arg()
+		call = func() { target.WriteScaled(text, xscale, yscale, color) }
+	case "Write":
+		text, color := a.text(), a.arg()
+		call = func() { target.Write(text, color) }
+	case "WriteRotated":
+		text, angle, color := a.text(), a.float(), a.arg()
+		call = func() { target.WriteRotated(text, angle, color) }
+	case "WriteRotatedScaled":
+		text, xscale, yscale, angle, color := a.text(), a.float(), a.float(), a.float(), a.arg()
+		call = func() { target.WriteRotatedScaled(text, xscale, yscale, angle, color) }
+	case "MoveCursor":
+		x, y := a.float(), a.float()
+		call = func() { target.MoveCursor(x, y) }
+	case "SetFontSampling":
+		sampling := a.sampling()
+		call = func() { target.SetFontSampling(sampling) }
+	case "SetFontSmoothing":
+		enabled, background := a.boolean(), a.arg()
+		call = func() { target.SetFontSmoothing(enabled, background) }
+	case "SetLetterSpacing":
+		spacing := a.float()
+		call = func() { target.SetLetterSpacing(spacing) }
+	case "SetWordSpacing":
+		spacing := a.float()
+		call = func() { target.SetWordSpacing(spacing) }
+	case "SetKerning":
+		kerning := a.kerning()
+		call = func() { target.SetKerning(kerning) }
+	default:
+		return nil, fmt.Errorf("unknown command %q", c.Name)
+	}
+	if err := a.done(); err != nil {
+		return nil, err
+	}
+	return func() error {
+		call()
+		return nil
+	}, nil
+}
@@ -0,0 +1,310 @@
+// Package recorder keeps the drawing calls of a Sketcher in a display list that can be
+// replayed on other sketchers, compared between frames or checked in tests.
+package recorder
+
+import (
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/internal/null"
+)
+
+// Command is one recorded Sketcher call, Name is the method name and Args are its arguments.
+type Command struct {
+	Name string
+	Args []any
+}
+
+// Recorder is a Sketcher that records the calls which change the drawing or its state.
+// Queries like GetTextArea are answered by a sketcher on a device of the same size, and
+// Err and DirtyRegions report what drawing on such a device would report.
+type Recorder struct {
+	sketcher drawings.Sketcher
+	commands []Command
+}
+
+func NewRecorder(width, height int, defaultColor any) *Recorder {
+	return &Recorder{
+		sketcher: drawings.NewSketcher(null.NewNullDevice(width, height), defaultColor),
+		commands: make([]Command, 0),
+	}
+}
+
+// Commands returns the display list recorded since the last Reset.
+func (r *Recorder) Commands() []Command {
+	commands := make([]Command, len(r.commands))
+	copy(commands, r.commands)
+	return commands
+}
+
+// Reset empties the display list, the drawing state like the font and rotation is kept.
+func (r *Recorder) Reset() {
+	r.commands = r.commands[:0]
+}
+
+// Replay applies the display list to the target in order and stops at the first invalid command.
+func (r *Recorder) Replay(target drawings.Sketcher) error {
+	for _, c := range r.commands {
+		if err := c.Apply(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) record(name string, args ...any) {
+	for i, arg := range args {
+		args[i] = copyPaint(arg)
+	}
+	r.commands = append(r.commands, Command{Name: name, Args: args})
+}
+
+// copyPaint keeps the stops of recorded gradients safe from changes by the caller.
+func copyPaint(arg any) any {
+	switch g := arg.(type) {
+	case drawings.LinearGradient:
+		g.Stops = append([]drawings.GradientStop{}, g.Stops...)
+		return g
+	case drawings.RadialGradient:
+		g.Stops = append([]drawings.GradientStop{}, g.Stops...)
+		return g
+	}
+	return arg
+}
+
+func copyPoints(points []drawings.Point) []drawings.Point {
+	return append([]drawings.Point{}, points...)
+}
+
+// copyPath keeps the recorded path safe from changes to the caller's path.
+func copyPath(path *drawings.Path) *drawings.Path {
+	return path.Transform(1, 0, 0, 1, 0, 0)
+}
+
+// Update starts a new error reporting period, the display list is not changed.
+func (r *Recorder) Update() int {
+	return r.sketcher.Update()
+}
+
+func (r *Recorder) Err() error {
+	return r.sketcher.Err()
+}
+
+func (r *Recorder) DirtyRegions() []drawings.Rect {
+	return r.sketcher.DirtyRegions()
+}
+
+func (r *Recorder) SetRotation(rotation float64) {
+	r.record("SetRotation", rotation)
+	r.sketcher.SetRotation(rotation)
+}
+
+func (r *Recorder) ScreenWidth() float64 {
+	return r.sketcher.ScreenWidth()
+}
+
+func (r *Recorder) ScreenHeight() float64 {
+	return r.sketcher.ScreenHeight()
+}
+
+func (r *Recorder) ClearArea(x1, y1, x2, y2 float64, color any) {
+	r.record("ClearArea", x1, y1, x2, y2, color)
+	r.sketcher.ClearArea(x1, y1, x2, y2, color)
+}
+
+func (r *Recorder) Clear(color any) {
+	r.record("Clear", color)
+	r.sketcher.Clear(color)
+}
+
+func (r *Recorder) Pixel(x, y float64, color any) {
+	r.record("Pixel", x, y, color)
+	r.sketcher.Pixel(x, y, color)
+}
+
+func (r *Recorder) Line(x1, y1, x2, y2 float64, color any) {
+	r.record("Line", x1, y1, x2, y2, color)
+	r.sketcher.Line(x1, y1, x2, y2, color)
+}
+
+func (r *Recorder) Arc(xc, yc, radius, startAngle, endAngle float64, color any) {
+	r.record("Arc", xc, yc, radius, startAngle, endAngle, color)
+	r.sketcher.Arc(xc, yc, radius, startAngle, endAngle, color)
+}
+
+func (r *Recorder) DirectedArc(xc, yc, radius, startAngle, endAngle float64, direction drawings.ArcDirection, color any) {
+	r.record("DirectedArc", xc, yc, radius, startAngle, endAngle, direction, color)
+	r.sketcher.DirectedArc(xc, yc, radius, startAngle, endAngle, direction, color)
+}
+
+func (r *Recorder) ThickArc(xc, yc, radius, startAngle, endAngle float64, width float64, widthType drawings.WidthType, color any) {
+	r.record("ThickArc", xc, yc, radius, startAngle, endAngle, width, widthType, color)
+	r.sketcher.ThickArc(xc, yc, radius, startAngle, endAngle, width, widthType, color)
+}
+
+func (r *Recorder) Circle(x, y, radius float64, color any) {
+	r.record("Circle", x, y, radius, color)
+	r.sketcher.Circle(x, y, radius, color)
+}
+
+func (r *Recorder) Rectangle(x1, y1, x2, y2 float64, color any) {
+	r.record("Rectangle", x1, y1, x2, y2, color)
+	r.sketcher.Rectangle(x1, y1, x2, y2, color)
+}
+
+func (r *Recorder) FillCircle(x, y, radius float64, color any) {
+	r.record("FillCircle", x, y, radius, color)
+	r.sketcher.FillCircle(x, y, radius, color)
+}
+
+func (r *Recorder) ThickCircle(x, y, radius float64, width float64, widthType drawings.WidthType, color any) {
+	r.record("ThickCircle", x, y, radius, width, widthType, color)
+	r.sketcher.ThickCircle(x, y, radius, width, widthType, color)
+}
+
+func (r *Recorder) FillRectangle(x1, y1, x2, y2 float64, color any) {
+	r.record("FillRectangle", x1, y1, x2, y2, color)
+	r.sketcher.FillRectangle(x1, y1, x2, y2, color)
+}
+
+func (r *Recorder) FillRectangleGradient(x1, y1, x2, y2 float64, from, to any, vertical bool) {
+	r.record("FillRectangleGradient", x1, y1, x2, y2, from, to, vertical)
+	r.sketcher.FillRectangleGradient(x1, y1, x2, y2, from, to, vertical)
+}
+
+func (r *Recorder) ThickRectangle(x1, y1, x2, y2 float64, width float64, widthType drawings.WidthType, color any) {
+	r.record("ThickRectangle", x1, y1, x2, y2, width, widthType, color)
+	r.sketcher.ThickRectangle(x1, y1, x2, y2, width, widthType, color)
+}
+
+func (r *Recorder) Polyline(points []drawings.Point, color any) {
+	r.record("Polyline", copyPoints(points), color)
+	r.sketcher.Polyline(points, color)
+}
+
+func (r *Recorder) ThickPolyline(points []drawings.Point, width float64, color any) {
+	r.record("ThickPolyline", copyPoints(points), width, color)
+	r.sketcher.ThickPolyline(points, width, color)
+}
+
+func (r *Recorder) FillPolygon(points []drawings.Point, color any) {
+	r.record("FillPolygon", copyPoints(points), color)
+	r.sketcher.FillPolygon(points, color)
+}
+
+func (r *Recorder) QuadBezier(x1, y1, cx, cy, x2, y2 float64, color any) {
+	r.record("QuadBezier", x1, y1, cx, cy, x2, y2, color)
+	r.sketcher.QuadBezier(x1, y1, cx, cy, x2, y2, color)
+}
+
+func (r *Recorder) CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color any) {
+	r.record("CubicBezier", x1, y1, cx1, cy1, cx2, cy2, x2, y2, color)
+	r.sketcher.CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2, color)
+}
+
+func (r *Recorder) Spline(points []drawings.Point, color any) {
+	r.record("Spline", copyPoints(points), color)
+	r.sketcher.Spline(points, color)
+}
+
+func (r *Recorder) ThickQuadBezier(x1, y1, cx, cy, x2, y2 float64, width float64, color any) {
+	r.record("ThickQuadBezier", x1, y1, cx, cy, x2, y2, width, color)
+	r.sketcher.ThickQuadBezier(x1, y1, cx, cy, x2, y2, width, color)
+}
+
+func (r *Recorder) ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, width float64, color any) {
+	r.record("ThickCubicBezier", x1, y1, cx1, cy1, cx2, cy2, x2, y2, width, color)
+	r.sketcher.ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2, width, color)
+}
+
+func (r *Recorder) ThickSpline(points []drawings.Point, width float64, color any) {
+	r.record("ThickSpline", copyPoints(points), width, color)
+	r.sketcher.ThickSpline(points, width, color)
+}
+
+func (r *Recorder) FillSpline(points []drawings.Point, color any) {
+	r.record("FillSpline", copyPoints(points), color)
+	r.sketcher.FillSpline(points, color)
+}
+
+func (r *Recorder) StrokePath(path *drawings.Path, width float64, color any) {
+	r.record("StrokePath", copyPath(path), width, color)
+	r.sketcher.StrokePath(path, width, color)
+}
+
+func (r *Recorder) FillPath(path *drawings.Path, rule drawings.FillRule, color any) {
+	r.record("FillPath", copyPath(path), rule, color)
+	r.sketcher.FillPath(path, rule, color)
+}
+
+func (r *Recorder) SetFont(font any) error {
+	r.record("SetFont", font)
+	return r.sketcher.SetFont(font)
+}
+
+func (r *Recorder) WriteScaled(text string, xscale, yscale float64, color any) {
+	r.record("WriteScaled", text, xscale, yscale, color)
+	r.sketcher.WriteScaled(text, xscale, yscale, color)
+}
+
+func (r *Recorder) Write(text string, color any) {
+	r.record("Write", text, color)
+	r.sketcher.Write(text, color)
+}
+
+func (r *Recorder) WriteRotated(text string, angle float64, color any) {
+	r.record("WriteRotated", text, angle, color)
+	r.sketcher.WriteRotated(text, angle, color)
+}
+
+func (r *Recorder) WriteRotatedScaled(text string, xscale, yscale, angle float64, color any) {
+	r.record("WriteRotatedScaled", text, xscale, yscale, angle, color)
+	r.sketcher.WriteRotatedScaled(text, xscale, yscale, angle, color)
+}
+
+func (r *Recorder) MoveCursor(x, y float64) {
+	r.record("MoveCursor", x, y)
+	r.sketcher.MoveCursor(x, y)
+}
+
+func (r *Recorder) GetTextArea(x, y float64, text string, xscale, yscale float64) (x1, y1, x2, y2 float64) {
+	return r.sketcher.GetTextArea(x, y, text, xscale, yscale)
+}
+
+func (r *Recorder) GetRotatedTextArea(x, y float64, text string, xscale, yscale, angle float64) (x1, y1, x2, y2 float64) {
+	return r.sketcher.GetRotatedTextArea(x, y, text, xscale, yscale, angle)
+}
+
+func (r *Recorder) GetTextScaleToFit(text string, width, height float64) float64 {
+	return r.sketcher.GetTextScaleToFit(text, width, height)
+}
+
+func (r *Recorder) SetFontSampling(sampling drawings.FontSampling) {
+	r.record("SetFontSampling", sampling)
+	r.sketcher.SetFontSampling(sampling)
+}
+
+func (r *Recorder) SetFontSmoothing(enabled bool, background any) {
+	r.record("SetFontSmoothing", enabled, background)
+	r.sketcher.SetFontSmoothing(enabled, background)
+}
+
+func (r *Recorder) SetLetterSpacing(spacing float64) {
+	r.record("SetLetterSpacing", spacing)
+	r.sketcher.SetLetterSpacing(spacing)
+}
+
+func (r *Recorder) SetWordSpacing(spacing float64) {
+	r.record("SetWordSpacing", spacing)
+	r.sketcher.SetWordSpacing(spacing)
+}
+
+func (r *Recorder) SetKerning(kerning drawings.KerningTable) {
+	var table drawings.KerningTable
+	if kerning != nil {
+		table = make(drawings.KerningTable, len(kerning))
+		for pair, offset := range kerning {
+			table[pair] = offset
+		}
+	}
+	r.record("SetKerning", table)
+	r.sketcher.SetKerning(kerning)
+}
@@ -0,0 +1,104 @@
+package recorder_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/devices/memory"
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/recorder"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+const (
+	WIDTH  = 64
+	HEIGHT = 48
+)
+
+// drawWidget draws a small gauge with most kinds of Sketcher calls.
+func drawWidget(s drawings.Sketcher, points []drawings.Point) {
+	s.Clear(drawings.Color{B: 40})
+	s.SetRotation(drawings.ROTATION_90)
+	s.FillRectangle(2, 2, 40, 20, drawings.LinearGradient{X2: 40, Stops: []drawings.GradientStop{
+		{Offset: 0, Color: drawings.Color{R: 255}},
+		{Offset: 1, Color: drawings.Color{G: 255}},
+	}})
+	s.ThickArc(24, 32, 14, 0, 3, 4, drawings.CENTER_WIDTH, 0xFFFF00)
+	s.Circle(24, 32, 6, "white")
+	s.Polyline(points, 0x00FFFF)
+	s.FillPath(drawings.NewPath().MoveTo(30, 30).LineTo(46, 30).QuadTo(40, 46, 30, 40).Close(), drawings.NON_ZERO, 0xFF00FF)
+	s.SetFont(fonts.FreeMono9pt7b)
+	s.MoveCursor(2, 60)
+	s.SetLetterSpacing(1)
+	s.Write("42", 0xFFFFFF)
+}
+
+func widgetPoints() []drawings.Point {
+	return []drawings.Point{{X: 2, Y: 40}, {X: 10, Y: 30}, {X: 20, Y: 44}}
+}
+
+// render draws on a memory device and returns its screen.
+func render(t *testing.T, draw func(s drawings.Sketcher)) []byte {
+	t.Helper()
+	dev := memory.NewMemoryDevice(WIDTH, HEIGHT)
+	s := drawings.NewSketcher(dev, 0)
+	draw(s)
+	s.Update()
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return dev.Image().Pix
+}
+
+func TestReplay(t *testing.T) {
+	points := widgetPoints()
+	r := recorder.NewRecorder(WIDTH, HEIGHT, 0)
+	drawWidget(r, points)
+	// the recording keeps the points as they were drawn
+	points[0].X = 1000
+
+	want := []string{"Clear", "SetRotation", "FillRectangle", "ThickArc", "Circle", "Polyline",
+		"FillPath", "SetFont", "MoveCursor", "SetLetterSpacing", "Write"}
+	var names []string
+	for _, c := range r.Commands() {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("recorded %v, want %v", names, want)
+	}
+
+	direct := render(t, func(s drawings.Sketcher) { drawWidget(s, widgetPoints()) })
+	replayed := render(t, func(s drawings.Sketcher) {
+		if err := r.Replay(s); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !bytes.Equal(replayed, direct) {
+		t.Error("the replayed drawing differs from drawing directly")
+	}
+
+	again := recorder.NewRecorder(WIDTH, HEIGHT, 0)
+	if err := r.Replay(again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again.Commands(), r.Commands()) {
+		t.Error("replaying into a recorder records other commands")
+	}
+	if !bytes.Equal(render(t, func(s drawings.Sketcher) { again.Replay(s) }), direct) {
+		t.Error("the recording of the replay draws another picture")
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := recorder.NewRecorder(WIDTH, HEIGHT, 0)
+	r.SetRotation(drawings.ROTATION_90)
+	r.Reset()
+	r.Write("A", 0xFFFFFF)
+	if commands := r.Commands(); len(commands) != 1 || commands[0].Name != "Write" {
+		t.Errorf("recorded %v after Reset", commands)
+	}
+	if width := r.ScreenWidth(); width != HEIGHT {
+		t.Errorf("screen width %v after Reset, the rotation is lost", width)
+	}
+}
@@ -0,0 +1,799 @@
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drawings-go/internal/null"
+	"github.com/marksaravi/fonts-go/fonts"
+)
+
+// ColorFormatter converts a drawing colour to an SVG colour value. The value is written into
+// the attribute as it is and has to be escaped.
+type ColorFormatter func(color any) (string, error)
+
+// FormatColor formats the colours drawings.ToColor takes as #rrggbb, strings are escaped and
+// used as they are so any SVG paint can be given.
+func FormatColor(color any) (string, error) {
+	if s, ok := color.(string); ok {
+		var b strings.Builder
+		if err := xml.EscapeText(&b, []byte(s)); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	c, err := drawings.ToColor(color)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+type element struct {
+	rotation int
+	markup   string
+}
+
+// sketcher implements drawings.Sketcher by recording every primitive as an SVG element.
+// Coordinates are pixel centres like on a pixel device, so the image matches the screen
+// pixel for pixel at a scale of one and stays sharp at any zoom.
+type sketcher struct {
+	width         int
+	height        int
+	rotation      int
+	background    string
+	backgroundDef string
+	elements      []element
+	gradients     int
+	updated       int
+	measure       drawings.Sketcher
+	bitmapFont    fonts.BitmapFont
+	hasFont       bool
+	cursorX       float64
+	cursorY       float64
+	letterSpacing float64
+	wordSpacing   float64
+	kerning       drawings.KerningTable
+	formatColor   ColorFormatter
+	err           error
+	dirty         drawings.Rect
+	isDirty       bool
+}
+
+// NewSketcher returns a Sketcher that draws into an SVG image of width x height pixels.
+func NewSketcher(width, height int, defaultColor any) *sketcher {
+	s := &sketcher{
+		width:       width,
+		height:      height,
+		rotation:    drawings.ROTATION_0,
+		elements:    make([]element, 0),
+		measure:     drawings.NewSketcher(null.NewNullDevice(width, height), defaultColor),
+		formatColor: FormatColor,
+	}
+	s.SetFont(fonts.FreeMono18pt7b)
+	return s
+}
+
+// SetColorFormatter replaces FormatColor for the colours of the following drawing calls.
+func (s *sketcher) SetColorFormatter(formatter ColorFormatter) {
+	s.formatColor = formatter
+}
+
+// WriteTo writes the SVG document of everything drawn since the last Clear.
+func (s *sketcher) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", s.width, s.height, s.width, s.height)
+	if s.background != "" {
+		b.WriteString(s.backgroundDef)
+		fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", s.width, s.height, s.background)
+	}
+	// pixel centres are at integer coordinates
+	b.WriteString(`<g transform="translate(0.5 0.5)">` + "\n")
+	rotation := drawings.ROTATION_0
+	for _, e := range s.elements {
+		if e.rotation != rotation {
+			if rotation != drawings.ROTATION_0 {
+				b.WriteString("</g>\n")
+			}
+			if e.rotation != drawings.ROTATION_0 {
+				fmt.Fprintf(&b, `<g transform="%s">`+"\n", s.rotationTransform(e.rotation))
+			}
+			rotation = e.rotation
+		}
+		b.WriteString(e.markup)
+		b.WriteString("\n")
+	}
+	if rotation != drawings.ROTATION_0 {
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n</svg>\n")
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// rotationTransform maps the rotated screen to the image like the pixel sketcher does.
+func (s *sketcher) rotationTransform(rotation int) string {
+	xmax := s.width - 1
+	ymax := s.height - 1
+	switch rotation {
+	case drawings.ROTATION_90:
+		return fmt.Sprintf("matrix(0 1 -1 0 %d 0)", xmax)
+	case drawings.ROTATION_180:
+		return fmt.Sprintf("matrix(-1 0 0 -1 %d %d)", xmax, ymax)
+	}
+	return fmt.Sprintf("matrix(0 -1 1 0 0 %d)", ymax)
+}
+
+// Update returns the number of elements drawn since the last Update and starts a new error reporting period.
+func (s *sketcher) Update() int {
+	n := len(s.elements) - s.updated
+	if n < 0 {
+		n = len(s.elements)
+	}
+	s.updated = len(s.elements)
+	s.err = nil
+	s.isDirty = false
+	return n
+}
+
+func (s *sketcher) Err() error {
+	return s.err
+}
+
+func (s *sketcher) setErr(err error) {
+	if s.err == nil && err != nil {
+		s.err = err
+	}
+}
+
+// DirtyRegions returns the bounding box of the image area drawn since the last Update.
+func (s *sketcher) DirtyRegions() []drawings.Rect {
+	if !s.isDirty {
+		return []drawings.Rect{}
+	}
+	return []drawings.Rect{s.dirty}
+}
+
+// markDirty extends the dirty area by the screen box x1, y1, x2, y2.
+func (s *sketcher) markDirty(x1, y1, x2, y2 float64) {
+	corners := [4][2]float64{{x1, y1}, {x2, y1}, {x2, y2}, {x1, y2}}
+	var r drawings.Rect
+	for i, c := range corners {
+		x, y := s.rotatePoint(c[0], c[1])
+		px := int(math.Floor(x + 0.5))
+		py := int(math.Floor(y + 0.5))
+		if i == 0 || px < r.X1 {
+			r.X1 = px
+		}
+		if i == 0 || py < r.Y1 {
+			r.Y1 = py
+		}
+		if i == 0 || px > r.X2 {
+			r.X2 = px
+		}
+		if i == 0 || py > r.Y2 {
+			r.Y2 = py
+		}
+	}
+	if r.X1 < 0 {
+		r.X1 = 0
+	}
+	if r.Y1 < 0 {
+		r.Y1 = 0
+	}
+	if r.X2 > s.width-1 {
+		r.X2 = s.width - 1
+	}
+	if r.Y2 > s.height-1 {
+		r.Y2 = s.height - 1
+	}
+	if r.X1 > r.X2 || r.Y1 > r.Y2 {
+		return
+	}
+	if !s.isDirty {
+		s.dirty = r
+		s.isDirty = true
+		return
+	}
+	if r.X1 < s.dirty.X1 {
+		s.dirty.X1 = r.X1
+	}
+	if r.Y1 < s.dirty.Y1 {
+		s.dirty.Y1 = r.Y1
+	}
+	if r.X2 > s.dirty.X2 {
+		s.dirty.X2 = r.X2
+	}
+	if r.Y2 > s.dirty.Y2 {
+		s.dirty.Y2 = r.Y2
+	}
+}
+
+func (s *sketcher) markPointsDirty(points []drawings.Point, margin float64) {
+	if len(points) == 0 {
+		return
+	}
+	x1, y1, x2, y2 := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, p := range points[1:] {
+		x1 = math.Min(x1, p.X)
+		y1 = math.Min(y1, p.Y)
+		x2 = math.Max(x2, p.X)
+		y2 = math.Max(y2, p.Y)
+	}
+	s.markDirty(x1-margin, y1-margin, x2+margin, y2+margin)
+}
+
+func (s *sketcher) rotatePoint(x, y float64) (float64, float64) {
+	xmax := float64(s.width - 1)
+	ymax := float64(s.height - 1)
+	switch s.rotation {
+	case drawings.ROTATION_90:
+		return xmax - y, x
+	case drawings.ROTATION_180:
+		return xmax - x, ymax - y
+	case drawings.ROTATION_270:
+		return y, ymax - x
+	}
+	return x, y
+}
+
+// add records an element, attrs follow the element name and the colour attribute is appended.
+func (s *sketcher) add(name, attrs, colorAttr string, color any) bool {
+	return s.addPainted(name, attrs, colorAttr, color, "")
+}
+
+// addPainted is add for elements with a transform, gradientTransform maps the user space
+// of the element back to the coordinates of the sketcher.
+func (s *sketcher) addPainted(name, attrs, colorAttr string, color any, gradientTransform string) bool {
+	def, c, err := s.paint(color, gradientTransform)
+	if err != nil {
+		s.setErr(err)
+		return false
+	}
+	s.elements = append(s.elements, element{
+		rotation: s.rotation,
+		markup:   fmt.Sprintf(`%s<%s %s %s="%s"/>`, def, name, attrs, colorAttr, c),
+	})
+	return true
+}
+
+// paint returns the colour value of the attribute, gradients are defined in def and referenced.
+func (s *sketcher) paint(color any, gradientTransform string) (def, value string, err error) {
+	var stops []drawings.GradientStop
+	var b strings.Builder
+	id := fmt.Sprintf("gradient%d", s.gradients+1)
+	transform := ""
+	if gradientTransform != "" {
+		transform = ` gradientTransform="` + gradientTransform + `"`
+	}
+	switch g := color.(type) {
+	case drawings.Solid:
+		return s.paint(g.Color, gradientTransform)
+	case drawings.LinearGradient:
+		fmt.Fprintf(&b, `<defs><linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s"%s>`,
+			id, num(g.X1), num(g.Y1), num(g.X2), num(g.Y2), transform)
+		stops = g.Stops
+	case drawings.RadialGradient:
+		fmt.Fprintf(&b, `<defs><radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s"%s>`,
+			id, num(g.X), num(g.Y), num(g.Radius), transform)
+		stops = g.Stops
+	default:
+		value, err = s.formatColor(color)
+		return "", value, err
+	}
+	for _, stop := range stops {
+		fmt.Fprintf(&b, `<stop offset="%s" stop-color="%s"/>`, num(stop.Offset), stop.Color)
+	}
+	if _, ok := color.(drawings.LinearGradient); ok {
+		b.WriteString("</linearGradient></defs>\n")
+	} else {
+		b.WriteString("</radialGradient></defs>\n")
+	}
+	s.gradients++
+	return b.String(), "url(#" + id + ")", nil
+}
+
+func (s *sketcher) stroke(name, attrs string, width float64, color any) bool {
+	return s.add(name, attrs+` fill="none" stroke-width="`+num(width)+`"`, "stroke", color)
+}
+
+func (s *sketcher) fill(name, attrs string, color any) bool {
+	return s.add(name, attrs, "fill", color)
+}
+
+func (s *sketcher) SetRotation(rotation float64) {
+	s.rotation = int(rotation)
+	s.measure.SetRotation(rotation)
+}
+
+func (s *sketcher) ScreenWidth() float64 {
+	if s.rotation == drawings.ROTATION_90 || s.rotation == drawings.ROTATION_270 {
+		return float64(s.height)
+	}
+	return float64(s.width)
+}
+
+func (s *sketcher) ScreenHeight() float64 {
+	if s.rotation == drawings.ROTATION_90 || s.rotation == drawings.ROTATION_270 {
+		return float64(s.width)
+	}
+	return float64(s.height)
+}
+
+func (s *sketcher) ClearArea(x1, y1, x2, y2 float64, color any) {
+	s.FillRectangle(x1, y1, x2, y2, color)
+}
+
+// Clear drops everything drawn so far and fills the image with the colour.
+func (s *sketcher) Clear(color any) {
+	// the background is drawn before the pixel centre and rotation transforms
+	transform := "translate(0.5 0.5)"
+	if s.rotation != drawings.ROTATION_0 {
+		transform += " " + s.rotationTransform(s.rotation)
+	}
+	def, c, err := s.paint(color, transform)
+	if err != nil {
+		s.setErr(err)
+		return
+	}
+	s.background = c
+	s.backgroundDef = def
+	s.elements = s.elements[:0]
+	s.updated = 0
+	s.isDirty = true
+	s.dirty = drawings.Rect{X1: 0, Y1: 0, X2: s.width - 1, Y2: s.height - 1}
+}
+
+func (s *sketcher) Pixel(x, y float64, color any) {
+	if s.fill("rect", fmt.Sprintf(`x="%s" y="%s" width="1" height="1"`, num(x-0.5), num(y-0.5)), color) {
+		s.markDirty(x, y, x, y)
+	}
+}
+
+func (s *sketcher) Line(x1, y1, x2, y2 float64, color any) {
+	attrs := fmt.Sprintf(`x1="%s" y1="%s" x2="%s" y2="%s" stroke-linecap="square"`, num(x1), num(y1), num(x2), num(y2))
+	if s.stroke("line", attrs, 1, color) {
+		s.markDirty(math.Min(x1, x2), math.Min(y1, y2), math.Max(x1, x2), math.Max(y1, y2))
+	}
+}
+
+// arcPath returns the path data of the arc going clockwise from startAngle to endAngle.
+func arcPath(xc, yc, radius, startAngle, endAngle float64) string {
+	sweep := math.Mod(endAngle-startAngle, 2*math.Pi)
+	if sweep < 0 {
+		sweep += 2 * math.Pi
+	}
+	largeArc := 0
+	if sweep > math.Pi {
+		largeArc = 1
+	}
+	return fmt.Sprintf("M%s %sA%s %s 0 %d 1 %s %s",
+		num(xc+radius*math.Cos(startAngle)), num(yc+radius*math.Sin(startAngle)),
+		num(radius), num(radius), largeArc,
+		num(xc+radius*math.Cos(startAngle+sweep)), num(yc+radius*math.Sin(startAngle+sweep)))
+}
+
+func (s *sketcher) Arc(xc, yc, radius, startAngle, endAngle float64, color any) {
+	if drawings.IsFullCircle(startAngle, endAngle) {
+		s.Circle(xc, yc, radius, color)
+		return
+	}
+	if s.stroke("path", `d="`+arcPath(xc, yc, radius, startAngle, endAngle)+`"`, 1, color) {
+		s.markDirty(xc-radius, yc-radius, xc+radius, yc+radius)
+	}
+}
+
+func (s *sketcher) DirectedArc(xc, yc, radius, startAngle, endAngle float64, direction drawings.ArcDirection, color any) {
+	if direction == drawings.COUNTER_CLOCKWISE {
+		startAngle, endAngle = endAngle, startAngle
+	}
+	s.Arc(xc, yc, radius, startAngle, endAngle, color)
+}
+
+func (s *sketcher) ThickArc(xc, yc, radius, startAngle, endAngle float64, width float64, widthType drawings.WidthType, color any) {
+	ro := drawings.ThicknessStart(radius, width, widthType)
+	if drawings.IsFullCircle(startAngle, endAngle) {
+		s.ThickCircle(xc, yc, ro-width/2, width, drawings.CENTER_WIDTH, color)
+		return
+	}
+	if s.stroke("path", `d="`+arcPath(xc, yc, ro-width/2, startAngle, endAngle)+`"`, width, color) {
+		s.markDirty(xc-ro, yc-ro, xc+ro, yc+ro)
+	}
+}
+
+func (s *sketcher) Circle(x, y, radius float64, color any) {
+	if s.stroke("circle", fmt.Sprintf(`cx="%s" cy="%s" r="%s"`, num(x), num(y), num(radius)), 1, color) {
+		s.markDirty(x-radius, y-radius, x+radius, y+radius)
+	}
+}
+
+func (s *sketcher) FillCircle(x, y, radius float64, color any) {
+	// the fill covers the outline pixels of Circle
+	if s.fill("circle", fmt.Sprintf(`cx="%s" cy="%s" r="%s"`, num(x), num(y), num(radius+0.5)), color) {
+		s.markDirty(x-radius, y-radius, x+radius, y+radius)
+	}
+}
+
+func (s *sketcher) ThickCircle(x, y, radius float64, width float64, widthType drawings.WidthType, color any) {
+	ro := drawings.ThicknessStart(radius, width, widthType)
+	if s.stroke("circle", fmt.Sprintf(`cx="%s" cy="%s" r="%s"`, num(x), num(y), num(ro-width/2)), width, color) {
+		s.markDirty(x-ro, y-ro, x+ro, y+ro)
+	}
+}
+
+func rectAttrs(x1, y1, x2, y2, grow float64) string {
+	x := math.Min(x1, x2) - grow
+	y := math.Min(y1, y2) - grow
+	return fmt.Sprintf(`x="%s" y="%s" width="%s" height="%s"`, num(x), num(y), num(math.Abs(x2-x1)+2*grow), num(math.Abs(y2-y1)+2*grow))
+}
+
+func (s *sketcher) Rectangle(x1, y1, x2, y2 float64, color any) {
+	if s.stroke("rect", rectAttrs(x1, y1, x2, y2, 0), 1, color) {
+		s.markDirty(math.Min(x1, x2), math.Min(y1, y2), math.Max(x1, x2), math.Max(y1, y2))
+	}
+}
+
+func (s *sketcher) FillRectangle(x1, y1, x2, y2 float64, color any) {
+	// both corners are covered like on the screen
+	if s.fill("rect", rectAttrs(x1, y1, x2, y2, 0.5), color) {
+		s.markDirty(math.Min(x1, x2), math.Min(y1, y2), math.Max(x1, x2), math.Max(y1, y2))
+	}
+}
+
+func (s *sketcher) FillRectangleGradient(x1, y1, x2, y2 float64, from, to any, vertical bool) {
+	g, err := drawings.RectangleGradient(x1, y1, x2, y2, from, to, vertical)
+	if err != nil {
+		s.setErr(err)
+		return
+	}
+	s.FillRectangle(x1, y1, x2, y2, g)
+}
+
+// ThickRectangle strokes the middle of the band covered by the nested outlines of the pixel sketcher.
+func (s *sketcher) ThickRectangle(x1, y1, x2, y2 float64, width float64, widthType drawings.WidthType, color any) {
+	if width <= 0 {
+		return
+	}
+	outer := drawings.ThicknessStart(0, width, widthType)
+	band := math.Ceil(width)
+	grow := outer - (band-1)/2
+	if s.stroke("rect", rectAttrs(x1, y1, x2, y2, grow), band, color) {
+		s.markDirty(math.Min(x1, x2)-outer, math.Min(y1, y2)-outer, math.Max(x1, x2)+outer, math.Max(y1, y2)+outer)
+	}
+}
+
+func pointsData(points []drawings.Point, closed bool) string {
+	var b strings.Builder
+	for i, p := range points {
+		if i == 0 {
+			b.WriteString("M")
+		} else {
+			b.WriteString("L")
+		}
+		b.WriteString(num(p.X) + " " + num(p.Y))
+	}
+	if closed {
+		b.WriteString("Z")
+	}
+	return b.String()
+}
+
+func (s *sketcher) Polyline(points []drawings.Point, color any) {
+	if len(points) == 1 {
+		s.Pixel(points[0].X, points[0].Y, color)
+		return
+	}
+	s.strokeData(pointsData(points, false), points, 1, color)
+}
+
+// strokeData strokes path data, one pixel lines end on their end pixels and wider
+// lines have round joins and ends like on the screen.
+func (s *sketcher) strokeData(d string, points []drawings.Point, width float64, color any) {
+	if len(points) == 0 {
+		return
+	}
+	attrs := `d="` + d + `" stroke-linejoin="round" stroke-linecap="round"`
+	if width <= 1 {
+		width = 1
+		attrs = `d="` + d + `" stroke-linejoin="miter" stroke-linecap="square"`
+	}
+	if s.stroke("path", attrs, width, color) {
+		s.markPointsDirty(points, width/2)
+	}
+}
+
+func (s *sketcher) fillData(d string, points []drawings.Point, rule drawings.FillRule, color any) {
+	if len(points) == 0 {
+		return
+	}
+	attrs := `d="` + d + `"`
+	if rule == drawings.EVEN_ODD {
+		attrs += ` fill-rule="evenodd"`
+	}
+	if s.fill("path", attrs, color) {
+		s.markPointsDirty(points, 0)
+	}
+}
+
+func (s *sketcher) ThickPolyline(points []drawings.Point, width float64, color any) {
+	if width <= 0 {
+		return
+	}
+	s.strokeData(pointsData(points, false), points, width, color)
+}
+
+func (s *sketcher) FillPolygon(points []drawings.Point, color any) {
+	s.fillData(pointsData(points, true), points, drawings.NON_ZERO, color)
+}
+
+func quadData(x1, y1, cx, cy, x2, y2 float64) string {
+	return fmt.Sprintf("M%s %sQ%s %s %s %s", num(x1), num(y1), num(cx), num(cy), num(x2), num(y2))
+}
+
+func cubicData(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64) string {
+	return fmt.Sprintf("M%s %sC%s %s %s %s %s %s", num(x1), num(y1), num(cx1), num(cy1), num(cx2), num(cy2), num(x2), num(y2))
+}
+
+// splineData converts the Catmull-Rom spline through the points to cubic curves.
+func splineData(points []drawings.Point, closed bool) string {
+	n := len(points)
+	if n < 3 {
+		return pointsData(points, closed)
+	}
+	at := func(i int) drawings.Point {
+		if closed {
+			return points[(i+n)%n]
+		}
+		if i < 0 {
+			return points[0]
+		}
+		if i >= n {
+			return points[n-1]
+		}
+		return points[i]
+	}
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	var b strings.Builder
+	b.WriteString("M" + num(points[0].X) + " " + num(points[0].Y))
+	for i := 0; i < segments; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		fmt.Fprintf(&b, "C%s %s %s %s %s %s",
+			num(p1.X+(p2.X-p0.X)/6), num(p1.Y+(p2.Y-p0.Y)/6),
+			num(p2.X-(p3.X-p1.X)/6), num(p2.Y-(p3.Y-p1.Y)/6),
+			num(p2.X), num(p2.Y))
+	}
+	if closed {
+		b.WriteString("Z")
+	}
+	return b.String()
+}
+
+func (s *sketcher) QuadBezier(x1, y1, cx, cy, x2, y2 float64, color any) {
+	s.strokeData(quadData(x1, y1, cx, cy, x2, y2), []drawings.Point{{X: x1, Y: y1}, {X: cx, Y: cy}, {X: x2, Y: y2}}, 1, color)
+}
+
+func (s *sketcher) CubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color any) {
+	points := []drawings.Point{{X: x1, Y: y1}, {X: cx1, Y: cy1}, {X: cx2, Y: cy2}, {X: x2, Y: y2}}
+	s.strokeData(cubicData(x1, y1, cx1, cy1, cx2, cy2, x2, y2), points, 1, color)
+}
+
+func (s *sketcher) Spline(points []drawings.Point, color any) {
+	s.strokeData(splineData(points, false), points, 1, color)
+}
+
+func (s *sketcher) ThickQuadBezier(x1, y1, cx, cy, x2, y2 float64, width float64, color any) {
+	if width <= 0 {
+		return
+	}
+	points := []drawings.Point{{X: x1, Y: y1}, {X: cx, Y: cy}, {X: x2, Y: y2}}
+	s.strokeData(quadData(x1, y1, cx, cy, x2, y2), points, width, color)
+}
+
+func (s *sketcher) ThickCubicBezier(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, width float64, color any) {
+	if width <= 0 {
+		return
+	}
+	points := []drawings.Point{{X: x1, Y: y1}, {X: cx1, Y: cy1}, {X: cx2, Y: cy2}, {X: x2, Y: y2}}
+	s.strokeData(cubicData(x1, y1, cx1, cy1, cx2, cy2, x2, y2), points, width, color)
+}
+
+func (s *sketcher) ThickSpline(points []drawings.Point, width float64, color any) {
+	if width <= 0 {
+		return
+	}
+	s.strokeData(splineData(points, false), points, width, color)
+}
+
+func (s *sketcher) FillSpline(points []drawings.Point, color any) {
+	s.fillData(splineData(points, true), points, drawings.NON_ZERO, color)
+}
+
+// pathData returns the SVG path data of the path and all its points.
+func pathData(path *drawings.Path) (string, []drawings.Point) {
+	var b strings.Builder
+	points := make([]drawings.Point, 0)
+	commands := map[drawings.PathOp]string{
+		drawings.MOVE_TO:  "M",
+		drawings.LINE_TO:  "L",
+		drawings.QUAD_TO:  "Q",
+		drawings.CUBIC_TO: "C",
+		drawings.CLOSE:    "Z",
+	}
+	for _, segment := range path.Segments() {
+		b.WriteString(commands[segment.Op])
+		for i, p := range segment.Points {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(num(p.X) + " " + num(p.Y))
+		}
+		points = append(points, segment.Points...)
+	}
+	return b.String(), points
+}
+
+func (s *sketcher) StrokePath(path *drawings.Path, width float64, color any) {
+	d, points := pathData(path)
+	s.strokeData(d, points, width, color)
+}
+
+func (s *sketcher) FillPath(path *drawings.Path, rule drawings.FillRule, color any) {
+	d, points := pathData(path)
+	s.fillData(d, points, rule, color)
+}
+
+// SetFont supports the bitmap fonts of fonts-go, like the pixel sketcher.
+func (s *sketcher) SetFont(font any) error {
+	if err := s.measure.SetFont(font); err != nil {
+		return err
+	}
+	s.bitmapFont = font.(fonts.BitmapFont)
+	s.hasFont = true
+	return nil
+}
+
+func (s *sketcher) Write(text string, color any) {
+	s.writeText(text, 1, 1, 0, color)
+}
+
+func (s *sketcher) WriteScaled(text string, xscale, yscale float64, color any) {
+	s.writeText(text, drawings.ClampFontScale(xscale), drawings.ClampFontScale(yscale), 0, color)
+}
+
+func (s *sketcher) WriteRotated(text string, angle float64, color any) {
+	s.writeText(text, 1, 1, angle, color)
+}
+
+func (s *sketcher) WriteRotatedScaled(text string, xscale, yscale, angle float64, color any) {
+	s.writeText(text, drawings.ClampFontScale(xscale), drawings.ClampFontScale(yscale), angle, color)
+}
+
+// writeText draws the text as one path of glyph pixel runs in font units, placed with a
+// transform. Glyph pixels are drawn over the screen pixels they cover.
+func (s *sketcher) writeText(text string, xscale, yscale, angle float64, color any) {
+	if !s.hasFont {
+		s.setErr(drawings.ErrFontNotDefined)
+		return
+	}
+	var b strings.Builder
+	x := 0.0
+	var prev byte = 0
+	for i := 0; i < len(text); i++ {
+		char := text[i]
+		if char < ' ' || char > '~' {
+			s.setErr(drawings.ErrCharOutOfRange)
+			continue
+		}
+		if int(char-0x20) >= len(s.bitmapFont.Glyphs) {
+			s.setErr(drawings.ErrFontNotDefined)
+			continue
+		}
+		if prev != 0 && s.kerning != nil {
+			x += s.kerning[drawings.KerningPair{Left: prev, Right: char}]
+		}
+		prev = char
+		glyph := s.bitmapFont.Glyphs[char-0x20]
+		s.glyphRuns(&b, glyph, x)
+		x += float64(glyph.XAdvance) + s.letterSpacing
+		if char == ' ' {
+			x += s.wordSpacing
+		}
+	}
+	startX, startY := s.cursorX, s.cursorY
+	s.cursorX += x * xscale * math.Cos(angle)
+	s.cursorY += x * xscale * math.Sin(angle)
+	if b.Len() == 0 {
+		return
+	}
+	transform := "translate(" + num(startX-0.5) + " " + num(startY-0.5) + ")"
+	if angle != 0 {
+		transform += " rotate(" + num(drawings.RadToDeg(angle)) + ")"
+	}
+	if xscale != 1 || yscale != 1 {
+		transform += " scale(" + num(xscale) + " " + num(yscale) + ")"
+	}
+	// gradients are given in screen coordinates, not in font units
+	inverse := "translate(" + num(0.5-startX) + " " + num(0.5-startY) + ")"
+	if angle != 0 {
+		inverse = "rotate(" + num(-drawings.RadToDeg(angle)) + ") " + inverse
+	}
+	if xscale != 1 || yscale != 1 {
+		inverse = "scale(" + num(1/xscale) + " " + num(1/yscale) + ") " + inverse
+	}
+	if s.addPainted("path", `transform="`+transform+`" d="`+b.String()+`"`, "fill", color, inverse) {
+		s.markDirty(s.measure.GetRotatedTextArea(startX, startY, text, xscale, yscale, angle))
+	}
+}
+
+// glyphRuns adds a rectangle for every horizontal run of set pixels in the glyph.
+func (s *sketcher) glyphRuns(b *strings.Builder, glyph fonts.Glyph, x float64) {
+	for h := 0; h < glyph.Height; h++ {
+		for w := 0; w < glyph.Width; w++ {
+			if !drawings.GlyphPixel(s.bitmapFont, glyph, w, h) {
+				continue
+			}
+			start := w
+			for w+1 < glyph.Width && drawings.GlyphPixel(s.bitmapFont, glyph, w+1, h) {
+				w++
+			}
+			fmt.Fprintf(b, "M%s %dh%dv1h-%dz", num(x+float64(glyph.XOffset+start)), glyph.YOffset+h, w-start+1, w-start+1)
+		}
+	}
+}
+
+func (s *sketcher) MoveCursor(x, y float64) {
+	s.cursorX = x
+	s.cursorY = y
+}
+
+func (s *sketcher) GetTextArea(x, y float64, text string, xscale, yscale float64) (x1, y1, x2, y2 float64) {
+	return s.measure.GetTextArea(x, y, text, xscale, yscale)
+}
+
+func (s *sketcher) GetRotatedTextArea(x, y float64, text string, xscale, yscale, angle float64) (x1, y1, x2, y2 float64) {
+	return s.measure.GetRotatedTextArea(x, y, text, xscale, yscale, angle)
+}
+
+func (s *sketcher) GetTextScaleToFit(text string, width, height float64) float64 {
+	return s.measure.GetTextScaleToFit(text, width, height)
+}
+
+// SetFontSampling has no effect, glyphs are exported as shapes and scale without sampling.
+func (s *sketcher) SetFontSampling(sampling drawings.FontSampling) {
+}
+
+// SetFontSmoothing has no effect, the viewer smooths the glyph shapes.
+func (s *sketcher) SetFontSmoothing(enabled bool, background any) {
+}
+
+func (s *sketcher) SetLetterSpacing(spacing float64) {
+	s.letterSpacing = spacing
+	s.measure.SetLetterSpacing(spacing)
+}
+
+func (s *sketcher) SetWordSpacing(spacing float64) {
+	s.wordSpacing = spacing
+	s.measure.SetWordSpacing(spacing)
+}
+
+func (s *sketcher) SetKerning(kerning drawings.KerningTable) {
+	s.kerning = kerning
+	s.measure.SetKerning(kerning)
+}
+
+// num formats a coordinate with at most three decimals.
+func num(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
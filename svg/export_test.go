@@ -0,0 +1,54 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestFormatColor(t *testing.T) {
+	tests := map[any]string{
+		0x102030:                `#102030`,
+		"url(#pattern)":         `url(#pattern)`,
+		`red" onload="alert(1)`: `red&#34; onload=&#34;alert(1)`,
+		"<b>&":                  `&lt;b&gt;&amp;`,
+	}
+	for color, want := range tests {
+		got, err := FormatColor(color)
+		if err != nil || got != want {
+			t.Errorf("FormatColor(%v) = %q, %v, want %q", color, got, err, want)
+		}
+	}
+}
+
+func TestExportEscapesColors(t *testing.T) {
+	s := NewSketcher(20, 10, 0)
+	s.Clear(`black"/><script>alert(1)</script><rect fill="`)
+	s.FillRectangle(1, 1, 5, 5, `red" onload="alert(1)`)
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	doc := b.String()
+	decoder := xml.NewDecoder(&b)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%v in\n%s", err, doc)
+		}
+		if e, ok := token.(xml.StartElement); ok {
+			if e.Name.Local == "script" {
+				t.Errorf("colours are not escaped:\n%s", doc)
+			}
+			for _, a := range e.Attr {
+				if a.Name.Local == "onload" {
+					t.Errorf("colours are not escaped:\n%s", doc)
+				}
+			}
+		}
+	}
+}
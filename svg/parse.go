@@ -0,0 +1,319 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+// matrix is the affine transform [a c e; b d f].
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// multiply returns the transform applying n first and then m.
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+// scale is the average scaling of lengths, used for stroke widths.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
+
+// parseColor returns nil for "none", rgb() colours are read here and the others by drawings.ParseColor.
+func parseColor(value string) (*drawings.Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "none" || value == "transparent" {
+		return nil, nil
+	}
+	if value == "currentcolor" {
+		return &drawings.Color{R: 0, G: 0, B: 0}, nil
+	}
+	if strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")") {
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid color %q", value)
+		}
+		var c [3]uint8
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			scale := 1.0
+			if strings.HasSuffix(part, "%") {
+				part = part[:len(part)-1]
+				scale = 2.55
+			}
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid color %q", value)
+			}
+			c[i] = uint8(math.Max(0, math.Min(255, math.Round(v*scale))))
+		}
+		return &drawings.Color{R: c[0], G: c[1], B: c[2]}, nil
+	}
+	c, err := drawings.ParseColor(value)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func parseLength(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseNumbers reads a list of numbers separated by white space or commas.
+func parseNumbers(value string) ([]float64, error) {
+	s := &scanner{data: value}
+	numbers := make([]float64, 0)
+	for {
+		s.skipSeparators()
+		if s.done() {
+			return numbers, nil
+		}
+		v, err := s.number()
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, v)
+	}
+}
+
+func parseTransform(value string) (matrix, error) {
+	m := identity
+	rest := strings.TrimSpace(value)
+	for len(rest) > 0 {
+		open := strings.Index(rest, "(")
+		closing := strings.Index(rest, ")")
+		if open < 0 || closing < open {
+			return m, fmt.Errorf("invalid transform %q", value)
+		}
+		name := strings.TrimSpace(rest[:open])
+		args, err := parseNumbers(rest[open+1 : closing])
+		if err != nil {
+			return m, err
+		}
+		rest = strings.TrimLeft(rest[closing+1:], " \t\r\n,")
+
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				return m, fmt.Errorf("invalid transform %q", value)
+			}
+			t = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case "translate":
+			t = matrix{1, 0, 0, 1, arg(0, 0), arg(1, 0)}
+		case "scale":
+			sx := arg(0, 1)
+			t = matrix{sx, 0, 0, arg(1, sx), 0, 0}
+		case "rotate":
+			sin, cos := math.Sincos(drawings.DegToRad(arg(0, 0)))
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = matrix{1, 0, 0, 1, cx, cy}.multiply(matrix{cos, sin, -sin, cos, 0, 0}).multiply(matrix{1, 0, 0, 1, -cx, -cy})
+		case "skewX":
+			t = matrix{1, 0, math.Tan(drawings.DegToRad(arg(0, 0))), 1, 0, 0}
+		case "skewY":
+			t = matrix{1, math.Tan(drawings.DegToRad(arg(0, 0))), 0, 1, 0, 0}
+		default:
+			return m, fmt.Errorf("unknown transform %q", name)
+		}
+		m = m.multiply(t)
+	}
+	return m, nil
+}
+
+type scanner struct {
+	data string
+	pos  int
+}
+
+func (s *scanner) done() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *scanner) skipSeparators() {
+	for !s.done() && strings.IndexByte(" \t\r\n,", s.data[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *scanner) number() (float64, error) {
+	s.skipSeparators()
+	start := s.pos
+	if !s.done() && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+		s.pos++
+	}
+	digits := false
+	dot := false
+	for !s.done() {
+		c := s.data[s.pos]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		s.pos++
+	}
+	if digits && !s.done() && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		exp := s.pos
+		s.pos++
+		if !s.done() && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+		expDigits := false
+		for !s.done() && s.data[s.pos] >= '0' && s.data[s.pos] <= '9' {
+			s.pos++
+			expDigits = true
+		}
+		if !expDigits {
+			s.pos = exp
+		}
+	}
+	if !digits {
+		return 0, fmt.Errorf("number expected at %q", s.data[start:])
+	}
+	return strconv.ParseFloat(s.data[start:s.pos], 64)
+}
+
+// flag reads an arc flag, which may be written without a separator before the next number.
+func (s *scanner) flag() (bool, error) {
+	s.skipSeparators()
+	if s.done() || (s.data[s.pos] != '0' && s.data[s.pos] != '1') {
+		return false, fmt.Errorf("arc flag expected at %q", s.data[s.pos:])
+	}
+	s.pos++
+	return s.data[s.pos-1] == '1', nil
+}
+
+// hasNumber tells if another number follows, repeating the last path command.
+func (s *scanner) hasNumber() bool {
+	s.skipSeparators()
+	if s.done() {
+		return false
+	}
+	c := s.data[s.pos]
+	return (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '+'
+}
+
+// pathArgs is the number of arguments taken by each path command.
+var pathArgs = map[byte]int{'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0}
+
+func parsePathData(d string) (*drawings.Path, error) {
+	path := drawings.NewPath()
+	s := &scanner{data: d}
+	var x, y, startX, startY float64
+	// reflected control points for the smooth curve commands
+	var lastCubicX, lastCubicY, lastQuadX, lastQuadY float64
+	var lastCmd byte
+
+	for {
+		s.skipSeparators()
+		if s.done() {
+			return path, nil
+		}
+		cmd := s.data[s.pos]
+		if strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", cmd) < 0 {
+			return nil, fmt.Errorf("unknown path command %q", cmd)
+		}
+		s.pos++
+		relative := cmd >= 'a'
+		upper := cmd &^ 0x20
+
+		for first := true; first || (upper != 'Z' && s.hasNumber()); first = false {
+			ox, oy := 0.0, 0.0
+			if relative {
+				ox, oy = x, y
+			}
+			var values [7]float64
+			count := pathArgs[upper]
+			var largeArc, sweep bool
+			for i := 0; i < count; i++ {
+				var err error
+				switch {
+				case upper == 'A' && i == 3:
+					largeArc, err = s.flag()
+				case upper == 'A' && i == 4:
+					sweep, err = s.flag()
+				default:
+					values[i], err = s.number()
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			switch upper {
+			case 'M':
+				x, y = ox+values[0], oy+values[1]
+				startX, startY = x, y
+				if first {
+					path.MoveTo(x, y)
+				} else {
+					path.LineTo(x, y)
+				}
+			case 'L':
+				x, y = ox+values[0], oy+values[1]
+				path.LineTo(x, y)
+			case 'H':
+				x = ox + values[0]
+				path.LineTo(x, y)
+			case 'V':
+				y = oy + values[0]
+				path.LineTo(x, y)
+			case 'C':
+				path.CubicTo(ox+values[0], oy+values[1], ox+values[2], oy+values[3], ox+values[4], oy+values[5])
+				lastCubicX, lastCubicY = ox+values[2], oy+values[3]
+				x, y = ox+values[4], oy+values[5]
+			case 'S':
+				cx, cy := x, y
+				if lastCmd == 'C' || lastCmd == 'S' {
+					cx, cy = 2*x-lastCubicX, 2*y-lastCubicY
+				}
+				path.CubicTo(cx, cy, ox+values[0], oy+values[1], ox+values[2], oy+values[3])
+				lastCubicX, lastCubicY = ox+values[0], oy+values[1]
+				x, y = ox+values[2], oy+values[3]
+			case 'Q':
+				path.QuadTo(ox+values[0], oy+values[1], ox+values[2], oy+values[3])
+				lastQuadX, lastQuadY = ox+values[0], oy+values[1]
+				x, y = ox+values[2], oy+values[3]
+			case 'T':
+				cx, cy := x, y
+				if lastCmd == 'Q' || lastCmd == 'T' {
+					cx, cy = 2*x-lastQuadX, 2*y-lastQuadY
+				}
+				path.QuadTo(cx, cy, ox+values[0], oy+values[1])
+				lastQuadX, lastQuadY = cx, cy
+				x, y = ox+values[0], oy+values[1]
+			case 'A':
+				x, y = ox+values[5], oy+values[6]
+				path.ArcTo(values[0], values[1], drawings.DegToRad(values[2]), largeArc, sweep, x, y)
+			case 'Z':
+				path.Close()
+				x, y = startX, startY
+			}
+			lastCmd = upper
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package svg draws a practical subset of SVG images with a drawings.Sketcher:
+// path, rect, circle, ellipse, line, polyline and polygon elements inside nested
+// groups, with fill, stroke, stroke-width, fill-rule and transform attributes.
+//
+// NewSketcher goes the other way: a Sketcher that records the drawing as an SVG image.
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/marksaravi/drawings-go/drawings"
+	"github.com/marksaravi/drivers-go/colors"
+)
+
+// ColorFunc converts an SVG colour to the colour type of the device.
+type ColorFunc func(c drawings.Color) any
+
+type style struct {
+	fill        *drawings.Color
+	stroke      *drawings.Color
+	strokeWidth float64
+	fillRule    drawings.FillRule
+	transform   matrix
+	hidden      bool
+}
+
+type shape struct {
+	path        *drawings.Path
+	fill        *drawings.Color
+	stroke      *drawings.Color
+	strokeWidth float64
+	fillRule    drawings.FillRule
+}
+
+// Image is a parsed SVG image that can be drawn many times.
+type Image struct {
+	viewBox [4]float64
+	shapes  []shape
+}
+
+// Parse reads an SVG document.
+func Parse(r io.Reader) (*Image, error) {
+	decoder := xml.NewDecoder(r)
+	img := &Image{shapes: make([]shape, 0)}
+	black := drawings.Color{R: 0, G: 0, B: 0}
+	styles := []style{{fill: &black, stroke: nil, strokeWidth: 1, fillRule: drawings.NON_ZERO, transform: identity}}
+	hasRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			attrs := attributes(t)
+			st, err := applyStyle(styles[len(styles)-1], attrs)
+			if err != nil {
+				return nil, fmt.Errorf("svg <%s>: %v", t.Name.Local, err)
+			}
+			styles = append(styles, st)
+			if t.Name.Local == "svg" && !hasRoot {
+				hasRoot = true
+				if err := img.parseRoot(attrs); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if nonRendering[t.Name.Local] {
+				st.hidden = true
+				styles[len(styles)-1] = st
+			}
+			if st.hidden {
+				continue
+			}
+			path, err := elementPath(t.Name.Local, attrs)
+			if err != nil {
+				return nil, fmt.Errorf("svg <%s>: %v", t.Name.Local, err)
+			}
+			if path == nil {
+				continue
+			}
+			m := st.transform
+			img.shapes = append(img.shapes, shape{
+				path:        path.Transform(m.a, m.b, m.c, m.d, m.e, m.f),
+				fill:        st.fill,
+				stroke:      st.stroke,
+				strokeWidth: st.strokeWidth * m.scale(),
+				fillRule:    st.fillRule,
+			})
+		case xml.EndElement:
+			if len(styles) > 1 {
+				styles = styles[:len(styles)-1]
+			}
+		}
+	}
+	if !hasRoot {
+		return nil, errors.New("svg element is missing")
+	}
+	return img, nil
+}
+
+// nonRendering elements and their children are never drawn directly.
+var nonRendering = map[string]bool{
+	"defs": true, "clipPath": true, "mask": true, "symbol": true, "marker": true, "pattern": true,
+}
+
+func attributes(e xml.StartElement) map[string]string {
+	attrs := make(map[string]string)
+	for _, a := range e.Attr {
+		attrs[a.Name.Local] = strings.TrimSpace(a.Value)
+	}
+	// presentation attributes can also be given in the style attribute
+	for _, decl := range strings.Split(attrs["style"], ";") {
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) == 2 {
+			attrs[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return attrs
+}
+
+func (img *Image) parseRoot(attrs map[string]string) error {
+	if vb, ok := attrs["viewBox"]; ok {
+		values, err := parseNumbers(vb)
+		if err != nil || len(values) != 4 {
+			return fmt.Errorf("invalid viewBox %q", vb)
+		}
+		copy(img.viewBox[:], values)
+	} else {
+		width, err1 := parseLength(attrs["width"])
+		height, err2 := parseLength(attrs["height"])
+		if err1 != nil || err2 != nil {
+			return errors.New("svg needs a viewBox or a width and height")
+		}
+		img.viewBox = [4]float64{0, 0, width, height}
+	}
+	if img.viewBox[2] <= 0 || img.viewBox[3] <= 0 {
+		return errors.New("svg has an empty viewBox")
+	}
+	return nil
+}
+
+func applyStyle(st style, attrs map[string]string) (style, error) {
+	if v, ok := attrs["fill"]; ok {
+		c, err := parseColor(v)
+		if err != nil {
+			return st, err
+		}
+		st.fill = c
+	}
+	if v, ok := attrs["stroke"]; ok {
+		c, err := parseColor(v)
+		if err != nil {
+			return st, err
+		}
+		st.stroke = c
+	}
+	if v, ok := attrs["stroke-width"]; ok {
+		w, err := parseLength(v)
+		if err != nil {
+			return st, err
+		}
+		st.strokeWidth = w
+	}
+	if v, ok := attrs["fill-rule"]; ok {
+		st.fillRule = drawings.NON_ZERO
+		if v == "evenodd" {
+			st.fillRule = drawings.EVEN_ODD
+		}
+	}
+	if v, ok := attrs["transform"]; ok {
+		m, err := parseTransform(v)
+		if err != nil {
+			return st, err
+		}
+		st.transform = st.transform.multiply(m)
+	}
+	return st, nil
+}
+
+func elementPath(name string, attrs map[string]string) (*drawings.Path, error) {
+	number := func(key string) float64 {
+		v, _ := parseLength(attrs[key])
+		return v
+	}
+	switch name {
+	case "path":
+		return parsePathData(attrs["d"])
+	case "rect":
+		return rectPath(number("x"), number("y"), number("width"), number("height"), attrs), nil
+	case "circle":
+		r := number("r")
+		return ellipsePath(number("cx"), number("cy"), r, r), nil
+	case "ellipse":
+		return ellipsePath(number("cx"), number("cy"), number("rx"), number("ry")), nil
+	case "line":
+		return drawings.NewPath().MoveTo(number("x1"), number("y1")).LineTo(number("x2"), number("y2")), nil
+	case "polyline", "polygon":
+		values, err := parseNumbers(attrs["points"])
+		if err != nil {
+			return nil, err
+		}
+		path := drawings.NewPath()
+		for i := 0; i+1 < len(values); i += 2 {
+			if i == 0 {
+				path.MoveTo(values[i], values[i+1])
+			} else {
+				path.LineTo(values[i], values[i+1])
+			}
+		}
+		if name == "polygon" {
+			path.Close()
+		}
+		return path, nil
+	}
+	return nil, nil
+}
+
+func rectPath(x, y, width, height float64, attrs map[string]string) *drawings.Path {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	rx, errx := parseLength(attrs["rx"])
+	ry, erry := parseLength(attrs["ry"])
+	if errx != nil && erry == nil {
+		rx = ry
+	}
+	if erry != nil && errx == nil {
+		ry = rx
+	}
+	rx = math.Min(math.Max(rx, 0), width/2)
+	ry = math.Min(math.Max(ry, 0), height/2)
+	path := drawings.NewPath()
+	if rx == 0 || ry == 0 {
+		return path.MoveTo(x, y).LineTo(x+width, y).LineTo(x+width, y+height).LineTo(x, y+height).Close()
+	}
+	return path.MoveTo(x+rx, y).
+		LineTo(x+width-rx, y).ArcTo(rx, ry, 0, false, true, x+width, y+ry).
+		LineTo(x+width, y+height-ry).ArcTo(rx, ry, 0, false, true, x+width-rx, y+height).
+		LineTo(x+rx, y+height).ArcTo(rx, ry, 0, false, true, x, y+height-ry).
+		LineTo(x, y+ry).ArcTo(rx, ry, 0, false, true, x+rx, y).
+		Close()
+}
+
+func ellipsePath(cx, cy, rx, ry float64) *drawings.Path {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	return drawings.NewPath().
+		MoveTo(cx+rx, cy).
+		ArcTo(rx, ry, 0, false, true, cx-rx, cy).
+		ArcTo(rx, ry, 0, false, true, cx+rx, cy).
+		Close()
+}
+
+// Draw draws the image scaled into the box of width×height pixels from the pixel (x, y) keeping
+// its aspect ratio and centring it. toColor may be nil to draw with drivers-go RGB888 colours.
+func (img *Image) Draw(sketcher drawings.Sketcher, x, y, width, height float64, toColor ColorFunc) {
+	if toColor == nil {
+		toColor = func(c drawings.Color) any {
+			return colors.RGB888(c.RGB888())
+		}
+	}
+	vx, vy, vw, vh := img.viewBox[0], img.viewBox[1], img.viewBox[2], img.viewBox[3]
+	scale := math.Min(width/vw, height/vh)
+	// SVG pixels span from one integer to the next, the sketcher pixel centres are at integers
+	ox := x - 0.5 + (width-vw*scale)/2 - vx*scale
+	oy := y - 0.5 + (height-vh*scale)/2 - vy*scale
+	for _, s := range img.shapes {
+		path := s.path.Transform(scale, 0, 0, scale, ox, oy)
+		if s.fill != nil {
+			sketcher.FillPath(path, s.fillRule, toColor(*s.fill))
+		}
+		if s.stroke != nil && s.strokeWidth > 0 {
+			sketcher.StrokePath(path, s.strokeWidth*scale, toColor(*s.stroke))
+		}
+	}
+}
+
+// Render parses the SVG document and draws it into the box at (x, y).
+func Render(sketcher drawings.Sketcher, r io.Reader, x, y, width, height float64) error {
+	img, err := Parse(r)
+	if err != nil {
+		return err
+	}
+	img.Draw(sketcher, x, y, width, height, nil)
+	return nil
+}
@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/marksaravi/drawings-go/devices/memory"
+	"github.com/marksaravi/drawings-go/drawings"
+)
+
+const (
+	TEST_WIDTH  = 60
+	TEST_HEIGHT = 40
+)
+
+const testImage = `<svg xmlns="http://www.w3.org/2000/svg" width="60" height="40" viewBox="0 0 60 40">
+<rect x="2" y="3" width="20" height="12" fill="#204080"/>
+<g transform="translate(30 2) scale(2)" fill="red" stroke="white" stroke-width="0.5">
+  <path d="M1 1L10 3L4 9Z M3 3 Q6 1 8 4"/>
+  <circle cx="8" cy="12" r="4" fill="none"/>
+</g>
+<polygon points="5,20 25,22 15,38" fill="yellow" fill-rule="evenodd"/>
+<ellipse cx="45" cy="32" rx="10" ry="4" fill="#0f0"/>
+<polyline points="0,39 20,30 40,39" fill="none" stroke="blue" stroke-width="2"/>
+</svg>`
+
+// render draws the SVG document into a memory device of the test size.
+func render(t *testing.T, doc string) []byte {
+	t.Helper()
+	img, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, doc)
+	}
+	dev := memory.NewMemoryDevice(TEST_WIDTH, TEST_HEIGHT)
+	img.Draw(drawings.NewSketcher(dev, 0), 0, 0, TEST_WIDTH, TEST_HEIGHT, nil)
+	return dev.Image().Pix
+}
+
+// export draws the SVG document into an export sketcher and returns its document.
+func export(t *testing.T, doc string) string {
+	t.Helper()
+	img, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, doc)
+	}
+	s := NewSketcher(TEST_WIDTH, TEST_HEIGHT, 0)
+	img.Draw(s, 0, 0, TEST_WIDTH, TEST_HEIGHT, nil)
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestParseExportRoundTrip(t *testing.T) {
+	exported := export(t, testImage)
+	if !bytes.Equal(render(t, exported), render(t, testImage)) {
+		t.Errorf("the exported image draws differently:\n%s", exported)
+	}
+	if again := export(t, exported); again != exported {
+		t.Errorf("exporting the exported image changes it:\n%s\nbecame\n%s", exported, again)
+	}
+}
+
+// TestExportParseRoundTrip draws with the export sketcher and with a pixel sketcher, the
+// exported image must draw the same pixels where the shapes are exact in both.
+func TestExportParseRoundTrip(t *testing.T) {
+	draw := func(s drawings.Sketcher) {
+		s.Clear(0x102030)
+		s.FillRectangle(3, 4, 20, 15, 0xFF0000)
+		s.FillRectangle(40, 30, 25, 20, "white")
+		s.FillPolygon([]drawings.Point{{X: 30, Y: 2}, {X: 55, Y: 12}, {X: 35, Y: 18}}, drawings.Color{B: 255})
+		s.FillPath(drawings.NewPath().MoveTo(2, 20).LineTo(20, 20).LineTo(20, 38).Close(), drawings.EVEN_ODD, 0x00FF00)
+	}
+
+	pixel := memory.NewMemoryDevice(TEST_WIDTH, TEST_HEIGHT)
+	draw(drawings.NewSketcher(pixel, 0))
+	s := NewSketcher(TEST_WIDTH, TEST_HEIGHT, 0)
+	draw(s)
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(render(t, b.String()), pixel.Image().Pix) {
+		t.Errorf("the exported image draws differently:\n%s", b.String())
+	}
+}